@@ -9,11 +9,62 @@ import (
 // Node
 // -----------------------------------------------------------------------------
 
+// indentUnit is a single level of indentation used by multi-line String()s.
+const indentUnit = "    "
+
 type Node interface {
-	// TokenLiteral is used for debugging and testing.
-	TokenLiteral() string
+	// FirstTokenLiteral returns the literal of the first token of the node,
+	// e.g. "let" for a let statement. Used for debugging and testing.
+	FirstTokenLiteral() string
 	// String will allow us to print AST notes for debugging
 	String() string
+	// Accept visits the node with v, and then its children with the visitor
+	// returned by v.Visit, unless that is nil.
+	Accept(v Visitor)
+}
+
+// Visitor is called for every node of a traversal, as in go/ast. Returning a
+// nil Visitor skips the children of node, any other Visitor is used to visit
+// them.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// BaseVisitor visits every node and does nothing. It can be embedded to get a
+// Visitor, with Visit overridden by the embedding type.
+type BaseVisitor struct{}
+
+func (bv BaseVisitor) Visit(node Node) Visitor { return bv }
+
+// Walk traverses the tree rooted at node depth-first, as in go/ast: it calls
+// v.Visit(node), and unless that returns nil, walks each child of node with
+// the returned visitor.
+func Walk(v Visitor, node Node) {
+	accept(v, node)
+}
+
+// inspector adapts a func to a Visitor, for Inspect.
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect walks the tree rooted at node, calling f for each node. The children
+// of a node are skipped when f returns false for it.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// accept visits n unless it is missing, e.g. the value of a let statement that
+// failed to parse.
+func accept(v Visitor, n Node) {
+	if n != nil {
+		n.Accept(v)
+	}
 }
 
 // Statement is an identifier and an expression. For example:
@@ -42,11 +93,17 @@ type Expression interface {
 // Program is the root node of every AST.
 type Program struct {
 	Statements []Statement
+	// HasErrors is set by the parser when the program failed to parse, which
+	// tells a broken program apart from an empty one.
+	HasErrors bool
+	// Comments are the comments in the source, in order. They are not part
+	// of the tree, but are kept for the formatter.
+	Comments []token.Comment
 }
 
-func (p *Program) TokenLiteral() string {
+func (p *Program) FirstTokenLiteral() string {
 	if len(p.Statements) > 0 {
-		return p.Statements[0].TokenLiteral()
+		return p.Statements[0].FirstTokenLiteral()
 	} else {
 		return ""
 	}
@@ -60,6 +117,15 @@ func (p *Program) String() string {
 	return out.String()
 }
 
+func (p *Program) Accept(v Visitor) {
+	if v = v.Visit(p); v == nil {
+		return
+	}
+	for _, s := range p.Statements {
+		accept(v, s)
+	}
+}
+
 // Let Statement
 // -----------------------------------------------------------------------------
 
@@ -69,13 +135,13 @@ type LetStatement struct {
 	Value Expression
 }
 
-func (ls *LetStatement) statementNode()       {}
-func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) statementNode()            {}
+func (ls *LetStatement) FirstTokenLiteral() string { return ls.Token.Literal }
 
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ls.TokenLiteral() + " ")
+	out.WriteString(ls.FirstTokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
 	out.WriteString(" = ")
 
@@ -88,17 +154,26 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+func (ls *LetStatement) Accept(v Visitor) {
+	if v = v.Visit(ls); v == nil {
+		return
+	}
+	accept(v, ls.Name)
+	accept(v, ls.Value)
+}
+
 // Identifier
 // -----------------------------------------------------------------------------
 
 type Identifier struct {
-	Token token.Token // token.IDENT token
+	Token token.Token // token.NAME token
 	Value string
 }
 
-func (i *Identifier) expressionNode()      {}
-func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
-func (i *Identifier) String() string       { return i.Value }
+func (i *Identifier) expressionNode()           {}
+func (i *Identifier) FirstTokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Accept(v Visitor)          { v.Visit(i) }
+func (i *Identifier) String() string            { return i.Value }
 
 // Return Statement
 // -----------------------------------------------------------------------------
@@ -108,13 +183,13 @@ type ReturnStatement struct {
 	ReturnValue Expression
 }
 
-func (rs *ReturnStatement) statementNode()       {}
-func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) statementNode()            {}
+func (rs *ReturnStatement) FirstTokenLiteral() string { return rs.Token.Literal }
 
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(rs.TokenLiteral() + " ")
+	out.WriteString(rs.FirstTokenLiteral() + " ")
 	if rs.ReturnValue != nil {
 		out.WriteString(rs.ReturnValue.String())
 	}
@@ -123,6 +198,141 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+func (rs *ReturnStatement) Accept(v Visitor) {
+	if v = v.Visit(rs); v == nil {
+		return
+	}
+	accept(v, rs.ReturnValue)
+}
+
+// Import Statement
+// -----------------------------------------------------------------------------
+
+type ImportStatement struct {
+	Token token.Token // The 'import' token
+	Path  *StringLiteral
+}
+
+func (is *ImportStatement) statementNode()            {}
+func (is *ImportStatement) FirstTokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	return is.FirstTokenLiteral() + " \"" + is.Path.String() + "\";"
+}
+
+func (is *ImportStatement) Accept(v Visitor) {
+	if v = v.Visit(is); v == nil {
+		return
+	}
+	if is.Path != nil {
+		is.Path.Accept(v)
+	}
+}
+
+// Assign Statement
+// -----------------------------------------------------------------------------
+
+// AssignStatement rebinds an existing name. It is also an Expression, so that
+// sugar like ++x can be used wherever a value is expected.
+type AssignStatement struct {
+	Token token.Token // the name token, or ++/-- when desugared
+	Name  *Identifier
+	Value Expression
+}
+
+func (as *AssignStatement) statementNode()            {}
+func (as *AssignStatement) expressionNode()           {}
+func (as *AssignStatement) FirstTokenLiteral() string { return as.Token.Literal }
+
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(as.Name.String())
+	out.WriteString(" = ")
+	if as.Value != nil {
+		out.WriteString(as.Value.String())
+	}
+
+	return out.String()
+}
+
+func (as *AssignStatement) Accept(v Visitor) {
+	if v = v.Visit(as); v == nil {
+		return
+	}
+	accept(v, as.Name)
+	accept(v, as.Value)
+}
+
+// Index Assign Statement
+// -----------------------------------------------------------------------------
+
+// IndexAssignStatement stores a value at an index, e.g. arr[0] = 5. Like
+// AssignStatement, it is also an Expression, for ++arr[0].
+type IndexAssignStatement struct {
+	Token  token.Token // the first token of the target, or ++/-- when desugared
+	Target *IndexExpression
+	Value  Expression
+}
+
+func (is *IndexAssignStatement) statementNode()            {}
+func (is *IndexAssignStatement) expressionNode()           {}
+func (is *IndexAssignStatement) FirstTokenLiteral() string { return is.Token.Literal }
+
+func (is *IndexAssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.Target.String())
+	out.WriteString(" = ")
+	if is.Value != nil {
+		out.WriteString(is.Value.String())
+	}
+
+	return out.String()
+}
+
+func (is *IndexAssignStatement) Accept(v Visitor) {
+	if v = v.Visit(is); v == nil {
+		return
+	}
+	accept(v, is.Target)
+	accept(v, is.Value)
+}
+
+// Compound Assign Statement
+// -----------------------------------------------------------------------------
+
+// CompoundAssignStatement updates a name in place, e.g. x += 5.
+type CompoundAssignStatement struct {
+	Token    token.Token // the name token
+	Name     *Identifier
+	Operator string // +=, -=, *= or /=
+	Value    Expression
+}
+
+func (cs *CompoundAssignStatement) statementNode()            {}
+func (cs *CompoundAssignStatement) FirstTokenLiteral() string { return cs.Token.Literal }
+
+func (cs *CompoundAssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.Name.String())
+	out.WriteString(" " + cs.Operator + " ")
+	if cs.Value != nil {
+		out.WriteString(cs.Value.String())
+	}
+	out.WriteString(";")
+
+	return out.String()
+}
+
+func (cs *CompoundAssignStatement) Accept(v Visitor) {
+	if v = v.Visit(cs); v == nil {
+		return
+	}
+	accept(v, cs.Name)
+	accept(v, cs.Value)
+}
+
 // Expression Statement
 // -----------------------------------------------------------------------------
 
@@ -133,8 +343,8 @@ type ExpressionStatement struct {
 	Expression Expression
 }
 
-func (es *ExpressionStatement) statementNode()       {}
-func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) statementNode()            {}
+func (es *ExpressionStatement) FirstTokenLiteral() string { return es.Token.Literal }
 
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
@@ -143,6 +353,13 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+func (es *ExpressionStatement) Accept(v Visitor) {
+	if v = v.Visit(es); v == nil {
+		return
+	}
+	accept(v, es.Expression)
+}
+
 // Integer Literal Expression
 // -----------------------------------------------------------------------------
 
@@ -151,9 +368,76 @@ type IntegerLiteral struct {
 	Value int64
 }
 
-func (il *IntegerLiteral) expressionNode()      {}
-func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal } // "5"
-func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) expressionNode()           {}
+func (il *IntegerLiteral) FirstTokenLiteral() string { return il.Token.Literal } // "5"
+func (il *IntegerLiteral) Accept(v Visitor)          { v.Visit(il) }
+func (il *IntegerLiteral) String() string            { return il.Token.Literal }
+
+// Float Literal Expression
+// -----------------------------------------------------------------------------
+
+type FloatLiteral struct {
+	Token token.Token // 3.14
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()           {}
+func (fl *FloatLiteral) FirstTokenLiteral() string { return fl.Token.Literal } // "3.14"
+func (fl *FloatLiteral) Accept(v Visitor)          { v.Visit(fl) }
+func (fl *FloatLiteral) String() string            { return fl.Token.Literal }
+
+// String Literal Expression
+// -----------------------------------------------------------------------------
+
+type StringLiteral struct {
+	Token token.Token // "hello", with escapes already processed by the lexer
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()           {}
+func (sl *StringLiteral) FirstTokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Accept(v Visitor)          { v.Visit(sl) }
+func (sl *StringLiteral) String() string            { return sl.Value }
+
+// Interpolated String Expression
+// -----------------------------------------------------------------------------
+
+// InterpolatedString is an f-string such as f"Hello ${name}!". Its parts are
+// the text between interpolations, as *StringLiteral, and the interpolated
+// expressions, in source order. Empty text is left out.
+type InterpolatedString struct {
+	Token token.Token // the first token.STRING_PART
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()           {}
+func (is *InterpolatedString) FirstTokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("f\"")
+	for _, part := range is.Parts {
+		if text, ok := part.(*StringLiteral); ok {
+			out.WriteString(text.Value)
+			continue
+		}
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteString("}")
+	}
+	out.WriteString("\"")
+
+	return out.String()
+}
+
+func (is *InterpolatedString) Accept(v Visitor) {
+	if v = v.Visit(is); v == nil {
+		return
+	}
+	for _, part := range is.Parts {
+		accept(v, part)
+	}
+}
 
 // Boolean Literal Expression
 // -----------------------------------------------------------------------------
@@ -163,9 +447,22 @@ type BoolLiteral struct {
 	Value bool
 }
 
-func (bl *BoolLiteral) expressionNode()      {}
-func (bl *BoolLiteral) TokenLiteral() string { return bl.Token.Literal } // "true"
-func (bl *BoolLiteral) String() string       { return bl.Token.Literal }
+func (bl *BoolLiteral) expressionNode()           {}
+func (bl *BoolLiteral) FirstTokenLiteral() string { return bl.Token.Literal } // "true"
+func (bl *BoolLiteral) Accept(v Visitor)          { v.Visit(bl) }
+func (bl *BoolLiteral) String() string            { return bl.Token.Literal }
+
+// Null Literal Expression
+// -----------------------------------------------------------------------------
+
+type NullLiteral struct {
+	Token token.Token // null
+}
+
+func (nl *NullLiteral) expressionNode()           {}
+func (nl *NullLiteral) FirstTokenLiteral() string { return nl.Token.Literal }
+func (nl *NullLiteral) Accept(v Visitor)          { v.Visit(nl) }
+func (nl *NullLiteral) String() string            { return "null" }
 
 // Prefix Expression
 // -----------------------------------------------------------------------------
@@ -176,8 +473,8 @@ type PrefixExpression struct {
 	Right    Expression
 }
 
-func (pe *PrefixExpression) expressionNode()      {}
-func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) expressionNode()           {}
+func (pe *PrefixExpression) FirstTokenLiteral() string { return pe.Token.Literal }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -189,6 +486,13 @@ func (pe *PrefixExpression) String() string {
 	return out.String()
 }
 
+func (pe *PrefixExpression) Accept(v Visitor) {
+	if v = v.Visit(pe); v == nil {
+		return
+	}
+	accept(v, pe.Right)
+}
+
 // Infix Expression
 // -----------------------------------------------------------------------------
 
@@ -199,8 +503,8 @@ type InfixExpression struct {
 	Right    Expression
 }
 
-func (ie *InfixExpression) expressionNode()      {}
-func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) expressionNode()           {}
+func (ie *InfixExpression) FirstTokenLiteral() string { return ie.Token.Literal }
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -213,22 +517,95 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+func (ie *InfixExpression) Accept(v Visitor) {
+	if v = v.Visit(ie); v == nil {
+		return
+	}
+	accept(v, ie.Left)
+	accept(v, ie.Right)
+}
+
+// Ternary Expression
+// -----------------------------------------------------------------------------
+
+type TernaryExpression struct {
+	Token       token.Token // The '?' token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (te *TernaryExpression) expressionNode()           {}
+func (te *TernaryExpression) FirstTokenLiteral() string { return te.Token.Literal }
+func (te *TernaryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(te.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(te.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(te.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (te *TernaryExpression) Accept(v Visitor) {
+	if v = v.Visit(te); v == nil {
+		return
+	}
+	accept(v, te.Condition)
+	accept(v, te.Consequence)
+	accept(v, te.Alternative)
+}
+
 // Block Statement
 // -----------------------------------------------------------------------------
 
 type BlockStatement struct {
 	Token      token.Token // The '{' token
 	Statements []Statement
+	Rbrace     token.Position // position of the closing '}', if any
+}
+
+func (bs *BlockStatement) expressionNode()           {}
+func (bs *BlockStatement) FirstTokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) String() string            { return bs.IndentedString(0) }
+
+func (bs *BlockStatement) Accept(v Visitor) {
+	if v = v.Visit(bs); v == nil {
+		return
+	}
+	for _, s := range bs.Statements {
+		accept(v, s)
+	}
 }
 
-func (bs *BlockStatement) expressionNode()      {}
-func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
-func (bs *BlockStatement) String() string {
+// IndentedString renders the block over multiple lines, with its statements
+// indented one level deeper than depth:
+//
+//	{
+//	    x + y;
+//	    return z;
+//	}
+func (bs *BlockStatement) IndentedString(depth int) string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+
 	var out bytes.Buffer
 
+	outer := strings.Repeat(indentUnit, depth)
+	inner := outer + indentUnit
+
+	out.WriteString("{\n")
 	for _, s := range bs.Statements {
-		out.WriteString(s.String())
+		// nested blocks render themselves at depth 0, so re-indent every line
+		lines := strings.Split(s.String(), "\n")
+		out.WriteString(inner + strings.Join(lines, "\n"+inner) + "\n")
 	}
+	out.WriteString(outer + "}")
 
 	return out.String()
 }
@@ -243,24 +620,140 @@ type IfExpression struct {
 	Alternative *BlockStatement
 }
 
-func (ie *IfExpression) expressionNode()      {}
-func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) expressionNode()           {}
+func (ie *IfExpression) FirstTokenLiteral() string { return ie.Token.Literal }
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("if")
 	out.WriteString(ie.Condition.String())
 	out.WriteString(" ")
-	out.WriteString(ie.Consequence.String())
+	out.WriteString(ie.Consequence.IndentedString(0))
+
+	if ie.Alternative != nil {
+		out.WriteString(" else ")
+		out.WriteString(ie.Alternative.IndentedString(0))
+	}
 
+	return out.String()
+}
+
+func (ie *IfExpression) Accept(v Visitor) {
+	if v = v.Visit(ie); v == nil {
+		return
+	}
+	accept(v, ie.Condition)
+	if ie.Consequence != nil {
+		ie.Consequence.Accept(v)
+	}
 	if ie.Alternative != nil {
-		out.WriteString("else ")
-		out.WriteString(ie.Alternative.String())
+		ie.Alternative.Accept(v)
+	}
+}
+
+// While Statement
+// -----------------------------------------------------------------------------
+
+type WhileStatement struct {
+	Token     token.Token // The 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()            {}
+func (ws *WhileStatement) FirstTokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") ")
+	out.WriteString(ws.Body.IndentedString(0))
+
+	return out.String()
+}
+
+func (ws *WhileStatement) Accept(v Visitor) {
+	if v = v.Visit(ws); v == nil {
+		return
+	}
+	accept(v, ws.Condition)
+	if ws.Body != nil {
+		ws.Body.Accept(v)
 	}
+}
+
+// For Statement
+// -----------------------------------------------------------------------------
+
+// ForStatement is a C-style three-clause loop, any clause of which may be nil:
+//
+//	for (let i = 0; i < 10; i += 1) { ... }
+//	for (;;) { ... }
+type ForStatement struct {
+	Token     token.Token // The 'for' token
+	Init      Statement
+	Condition Expression
+	Post      Statement
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()            {}
+func (fs *ForStatement) FirstTokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString(";")
+	if fs.Condition != nil {
+		out.WriteString(" " + fs.Condition.String())
+	}
+	out.WriteString(";")
+	if fs.Post != nil {
+		out.WriteString(" " + strings.TrimSuffix(fs.Post.String(), ";"))
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.IndentedString(0))
 
 	return out.String()
 }
 
+func (fs *ForStatement) Accept(v Visitor) {
+	if v = v.Visit(fs); v == nil {
+		return
+	}
+	accept(v, fs.Init)
+	accept(v, fs.Condition)
+	accept(v, fs.Post)
+	if fs.Body != nil {
+		fs.Body.Accept(v)
+	}
+}
+
+// Break and Continue Statements
+// -----------------------------------------------------------------------------
+
+type BreakStatement struct {
+	Token token.Token // The 'break' token
+}
+
+func (bs *BreakStatement) statementNode()            {}
+func (bs *BreakStatement) FirstTokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Accept(v Visitor)          { v.Visit(bs) }
+func (bs *BreakStatement) String() string            { return "break;" }
+
+type ContinueStatement struct {
+	Token token.Token // The 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode()            {}
+func (cs *ContinueStatement) FirstTokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Accept(v Visitor)          { v.Visit(cs) }
+func (cs *ContinueStatement) String() string            { return "continue;" }
+
 // Function Literal Expression
 // -----------------------------------------------------------------------------
 
@@ -270,8 +763,8 @@ type FunctionLiteral struct {
 	Body       *BlockStatement
 }
 
-func (fl *FunctionLiteral) expressionNode()      {}
-func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) expressionNode()           {}
+func (fl *FunctionLiteral) FirstTokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
@@ -288,11 +781,24 @@ func (fl *FunctionLiteral) String() string {
 	out.WriteString(token.RPAREN)
 
 	// { .. }
-	out.WriteString(fl.Body.String())
+	out.WriteString(" ")
+	out.WriteString(fl.Body.IndentedString(0))
 
 	return out.String()
 }
 
+func (fl *FunctionLiteral) Accept(v Visitor) {
+	if v = v.Visit(fl); v == nil {
+		return
+	}
+	for _, param := range fl.Parameters {
+		accept(v, param)
+	}
+	if fl.Body != nil {
+		fl.Body.Accept(v)
+	}
+}
+
 // Call Expression
 // -----------------------------------------------------------------------------
 
@@ -302,8 +808,8 @@ type CallExpression struct {
 	Arguments []Expression
 }
 
-func (ce *CallExpression) expressionNode()      {}
-func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) expressionNode()           {}
+func (ce *CallExpression) FirstTokenLiteral() string { return ce.Token.Literal }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -319,3 +825,119 @@ func (ce *CallExpression) String() string {
 
 	return out.String()
 }
+
+func (ce *CallExpression) Accept(v Visitor) {
+	if v = v.Visit(ce); v == nil {
+		return
+	}
+	accept(v, ce.Function)
+	for _, arg := range ce.Arguments {
+		accept(v, arg)
+	}
+}
+
+// Array Literal Expression
+// -----------------------------------------------------------------------------
+
+type ArrayLiteral struct {
+	Token    token.Token // The '[' token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()           {}
+func (al *ArrayLiteral) FirstTokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	var elements []string
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString(token.LBRACKET)
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString(token.RBRACKET)
+
+	return out.String()
+}
+
+func (al *ArrayLiteral) Accept(v Visitor) {
+	if v = v.Visit(al); v == nil {
+		return
+	}
+	for _, el := range al.Elements {
+		accept(v, el)
+	}
+}
+
+// Hash Literal Expression
+// -----------------------------------------------------------------------------
+
+type HashPair struct {
+	Key, Value Expression
+}
+
+// HashLiteral keeps its pairs in source order.
+type HashLiteral struct {
+	Token token.Token // The '{' token
+	Pairs []HashPair
+}
+
+func (hl *HashLiteral) expressionNode()           {}
+func (hl *HashLiteral) FirstTokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	var pairs []string
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString(token.LBRACE)
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString(token.RBRACE)
+
+	return out.String()
+}
+
+func (hl *HashLiteral) Accept(v Visitor) {
+	if v = v.Visit(hl); v == nil {
+		return
+	}
+	for _, pair := range hl.Pairs {
+		accept(v, pair.Key)
+		accept(v, pair.Value)
+	}
+}
+
+// Index Expression
+// -----------------------------------------------------------------------------
+
+type IndexExpression struct {
+	Token token.Token // The '[' token
+	Left  Expression  // the array or hash being indexed
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()           {}
+func (ie *IndexExpression) FirstTokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString(token.LBRACKET)
+	out.WriteString(ie.Index.String())
+	out.WriteString(token.RBRACKET)
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (ie *IndexExpression) Accept(v Visitor) {
+	if v = v.Visit(ie); v == nil {
+		return
+	}
+	accept(v, ie.Left)
+	accept(v, ie.Index)
+}
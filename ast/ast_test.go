@@ -1,7 +1,11 @@
 package ast
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"monkey/token"
+	"reflect"
 	"testing"
 )
 
@@ -26,3 +30,230 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() is wrong, got=%q", got)
 	}
 }
+
+func TestBlockStatementString(t *testing.T) {
+	name := func(n string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.NAME, Literal: n}, Value: n}
+	}
+
+	block := &BlockStatement{
+		Token: token.Token{Type: token.LBRACE, Literal: "{"},
+		Statements: []Statement{
+			&ExpressionStatement{
+				Token: token.Token{Type: token.NAME, Literal: "x"},
+				Expression: &IfExpression{
+					Token:     token.Token{Type: token.IF, Literal: "if"},
+					Condition: name("x"),
+					Consequence: &BlockStatement{
+						Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+						Statements: []Statement{&ExpressionStatement{Expression: name("y")}},
+					},
+				},
+			},
+			&ReturnStatement{
+				Token:       token.Token{Type: token.RETURN, Literal: "return"},
+				ReturnValue: name("z"),
+			},
+		},
+	}
+
+	want := "{\n    ifx {\n        y\n    }\n    return z;\n}"
+	if got := block.String(); got != want {
+		t.Errorf("block.String() is wrong, got=%q", got)
+	}
+
+	want = "{\n        ifx {\n            y\n        }\n        return z;\n    }"
+	if got := block.IndentedString(1); got != want {
+		t.Errorf("block.IndentedString(1) is wrong, got=%q", got)
+	}
+}
+
+type integerCounter struct {
+	BaseVisitor
+	count int
+}
+
+func (ic *integerCounter) Visit(node Node) Visitor {
+	if _, ok := node.(*IntegerLiteral); ok {
+		ic.count++
+	}
+	return ic
+}
+
+func TestVisitorPattern(t *testing.T) {
+	integer := func(n int64, lit string) *IntegerLiteral {
+		return &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: lit}, Value: n}
+	}
+
+	// let x = 1 + 2; f([3, 4]);
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  &Identifier{Token: token.Token{Type: token.NAME, Literal: "x"}, Value: "x"},
+				Value: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Left:     integer(1, "1"),
+					Operator: "+",
+					Right:    integer(2, "2"),
+				},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.NAME, Literal: "f"},
+				Expression: &CallExpression{
+					Token:    token.Token{Type: token.LPAREN, Literal: "("},
+					Function: &Identifier{Token: token.Token{Type: token.NAME, Literal: "f"}, Value: "f"},
+					Arguments: []Expression{
+						&ArrayLiteral{
+							Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+							Elements: []Expression{integer(3, "3"), integer(4, "4")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	counter := &integerCounter{}
+	program.Accept(counter)
+	if counter.count != 4 {
+		t.Errorf("counter.count is wrong, want=4, got=%d", counter.count)
+	}
+
+	// BaseVisitor on its own walks the whole tree and does nothing
+	program.Accept(BaseVisitor{})
+}
+
+// letFunction builds the program: let x = fn(y) { x + y; }
+func letFunction() *Program {
+	name := func(n string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.NAME, Literal: n}, Value: n}
+	}
+
+	return &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  name("x"),
+				Value: &FunctionLiteral{
+					Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+					Parameters: []*Identifier{name("y")},
+					Body: &BlockStatement{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Statements: []Statement{
+							&ExpressionStatement{
+								Token: token.Token{Type: token.NAME, Literal: "x"},
+								Expression: &InfixExpression{
+									Token:    token.Token{Type: token.PLUS, Literal: "+"},
+									Left:     name("x"),
+									Operator: "+",
+									Right:    name("y"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	names := map[string]bool{}
+	Inspect(letFunction(), func(node Node) bool {
+		if ident, ok := node.(*Identifier); ok {
+			names[ident.Value] = true
+		}
+		return true
+	})
+
+	if len(names) != 2 || !names["x"] || !names["y"] {
+		t.Errorf("identifier names are wrong, want={x, y}, got=%v", names)
+	}
+}
+
+func TestInspectEarlyTermination(t *testing.T) {
+	var visited []string
+	Inspect(letFunction(), func(node Node) bool {
+		visited = append(visited, fmt.Sprintf("%T", node))
+		_, isFunction := node.(*FunctionLiteral)
+		return !isFunction
+	})
+
+	// the parameter and body of the function are never visited
+	want := []string{"*ast.Program", "*ast.LetStatement", "*ast.Identifier", "*ast.FunctionLiteral"}
+	if len(visited) != len(want) {
+		t.Fatalf("visited nodes are wrong, want=%q, got=%q", want, visited)
+	}
+	for i, typ := range want {
+		if visited[i] != typ {
+			t.Errorf("visited[%d] is wrong, want=%q, got=%q", i, typ, visited[i])
+		}
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	var out bytes.Buffer
+	PrintTree(letFunction(), &out, 0)
+
+	want := `Program
+    LetStatement
+        Identifier x
+        FunctionLiteral
+            Identifier y
+            BlockStatement
+                ExpressionStatement
+                    InfixExpression +
+                        Identifier x
+                        Identifier y
+`
+	if got := out.String(); got != want {
+		t.Errorf("PrintTree is wrong, got=\n%s\nwant=\n%s", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON(letFunction().Statements[0].(*LetStatement).Value)
+	if err != nil {
+		t.Fatalf("JSON failed: %s", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("JSON produced invalid JSON: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"type": "FunctionLiteral",
+		"Parameters": []interface{}{
+			map[string]interface{}{"type": "Identifier", "Value": "y"},
+		},
+		"Body": map[string]interface{}{
+			"type": "BlockStatement",
+			"Statements": []interface{}{
+				map[string]interface{}{
+					"type": "ExpressionStatement",
+					"Expression": map[string]interface{}{
+						"type":     "InfixExpression",
+						"Operator": "+",
+						"Left":     map[string]interface{}{"type": "Identifier", "Value": "x"},
+						"Right":    map[string]interface{}{"type": "Identifier", "Value": "y"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("JSON is wrong, got=%s", data)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	want := `let x = fn (y) {
+    x + y;
+};
+`
+	if got := Format(letFunction()); got != want {
+		t.Errorf("Format is wrong, got=\n%s\nwant=\n%s", got, want)
+	}
+}
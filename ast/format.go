@@ -0,0 +1,337 @@
+package ast
+
+import (
+	"monkey/token"
+	"reflect"
+	"strings"
+)
+
+// Format renders program as canonical Monkey source: one statement per line,
+// block bodies indented by four spaces with the opening brace on the same
+// line, spaces around binary operators, and only the parentheses the
+// precedence of operators calls for.
+//
+// The program's comments are written back between the statements they were
+// found between. A comment that followed code on its line still does, and
+// any other comment gets a line of its own. A comment within a statement
+// is moved after it. Sugar that the parser rewrites is rendered as what it
+// was rewritten to, e.g. x |> f as f(x).
+func Format(program *Program) string {
+	f := &formatter{comments: program.Comments}
+
+	var out strings.Builder
+	for _, s := range program.Statements {
+		f.writeComments(&out, statementOffset(s), 0)
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(f.statement(s, 0))
+	}
+	f.writeComments(&out, -1, 0)
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// formatter holds the comments that are still to be written, in source order.
+type formatter struct {
+	comments []token.Comment
+}
+
+// writeComments writes the comments before offset, or all of them if offset
+// is negative, to out, which is at the given block depth. Trailing comments
+// go at the end of the last line of out, and the others on lines of their
+// own.
+func (f *formatter) writeComments(out *strings.Builder, offset, depth int) {
+	for len(f.comments) > 0 && (offset < 0 || f.comments[0].Pos.Offset < offset) {
+		c := f.comments[0]
+		f.comments = f.comments[1:]
+
+		switch {
+		case c.Trailing && out.Len() > 0:
+			out.WriteString(" ")
+		case out.Len() > 0:
+			out.WriteString("\n")
+			fallthrough
+		default:
+			out.WriteString(strings.Repeat(indentUnit, depth))
+		}
+		out.WriteString(c.Text)
+	}
+}
+
+// statementOffset is the offset of the first token of s in the source.
+func statementOffset(s Statement) int {
+	tok := reflect.ValueOf(s).Elem().FieldByName("Token").Interface().(token.Token)
+	return tok.Pos.Offset
+}
+
+// Precedences of the formatted expressions, from loosest to tightest. They
+// follow the parser's, which the ast package cannot import.
+const (
+	fmtLowest = iota
+	fmtTernary
+	fmtOr
+	fmtAnd
+	fmtEquals
+	fmtLessGreater
+	fmtSum
+	fmtProduct
+	fmtPower
+	fmtPrefix
+	fmtCall
+)
+
+var fmtPrecedences = map[string]int{
+	"||": fmtOr,
+	"&&": fmtAnd,
+	"==": fmtEquals,
+	"!=": fmtEquals,
+	"<":  fmtLessGreater,
+	">":  fmtLessGreater,
+	"<=": fmtLessGreater,
+	">=": fmtLessGreater,
+	"+":  fmtSum,
+	"-":  fmtSum,
+	"*":  fmtProduct,
+	"/":  fmtProduct,
+	"%":  fmtProduct,
+	"**": fmtPower,
+}
+
+// statement renders s at the given block depth, which only affects the
+// lines after the first.
+func (f *formatter) statement(s Statement, depth int) string {
+	switch s := s.(type) {
+	case *LetStatement:
+		return "let " + s.Name.Value + " = " + f.expression(s.Value, depth) + ";"
+	case *ReturnStatement:
+		return "return " + f.expression(s.ReturnValue, depth) + ";"
+	case *ImportStatement:
+		return "import " + quote(s.Path.Value) + ";"
+	case *AssignStatement:
+		return f.assignment(s, depth) + ";"
+	case *IndexAssignStatement:
+		return f.indexAssignment(s, depth) + ";"
+	case *CompoundAssignStatement:
+		return s.Name.Value + " " + s.Operator + " " + f.expression(s.Value, depth) + ";"
+	case *ExpressionStatement:
+		// expressions ending in a block read like statements without the ;
+		if _, ok := s.Expression.(*IfExpression); ok {
+			return f.expression(s.Expression, depth)
+		}
+		return f.expression(s.Expression, depth) + ";"
+	case *WhileStatement:
+		return "while (" + f.expression(s.Condition, depth) + ") " + f.block(s.Body, depth)
+	case *ForStatement:
+		var clauses [3]string
+		if s.Init != nil {
+			clauses[0] = strings.TrimSuffix(f.statement(s.Init, depth), ";")
+		}
+		if s.Condition != nil {
+			clauses[1] = " " + f.expression(s.Condition, depth)
+		}
+		if s.Post != nil {
+			clauses[2] = " " + strings.TrimSuffix(f.statement(s.Post, depth), ";")
+		}
+		return "for (" + strings.Join(clauses[:], ";") + ") " + f.block(s.Body, depth)
+	case *BreakStatement:
+		return "break;"
+	case *ContinueStatement:
+		return "continue;"
+	default:
+		return s.String()
+	}
+}
+
+// block renders b with its statements one level deeper than depth, and its
+// closing brace at depth.
+func (f *formatter) block(b *BlockStatement, depth int) string {
+	var out strings.Builder
+	out.WriteString("{")
+	indent := strings.Repeat(indentUnit, depth+1)
+	for _, s := range b.Statements {
+		f.writeComments(&out, statementOffset(s), depth+1)
+		out.WriteString("\n" + indent)
+		out.WriteString(f.statement(s, depth+1))
+	}
+	if b.Rbrace.Offset > 0 {
+		f.writeComments(&out, b.Rbrace.Offset, depth+1)
+	}
+
+	if out.Len() == 1 {
+		return "{}"
+	}
+	out.WriteString("\n" + strings.Repeat(indentUnit, depth) + "}")
+	return out.String()
+}
+
+func (f *formatter) expression(e Expression, depth int) string {
+	switch e := e.(type) {
+	case *Identifier:
+		return e.Value
+	case *IntegerLiteral:
+		return e.Token.Literal
+	case *FloatLiteral:
+		return e.Token.Literal
+	case *StringLiteral:
+		return quote(e.Value)
+	case *InterpolatedString:
+		return f.interpolatedString(e, depth)
+	case *BoolLiteral, *NullLiteral:
+		return e.String()
+	case *PrefixExpression:
+		right := f.operand(e.Right, fmtPrefix, depth)
+		// --x would lex as a decrement
+		if e.Operator == "-" && strings.HasPrefix(right, "-") {
+			right = "(" + right + ")"
+		}
+		return e.Operator + right
+	case *InfixExpression:
+		precedence := fmtPrecedences[e.Operator]
+		left, right := precedence, precedence+1
+		if e.Operator == "**" { // right-associative
+			left, right = precedence+1, precedence
+		}
+		return f.operand(e.Left, left, depth) + " " + e.Operator + " " + f.operand(e.Right, right, depth)
+	case *TernaryExpression:
+		return f.operand(e.Condition, fmtOr, depth) + " ? " +
+			f.expression(e.Consequence, depth) + " : " +
+			f.operand(e.Alternative, fmtTernary, depth)
+	case *AssignStatement:
+		return f.assignment(e, depth)
+	case *IndexAssignStatement:
+		return f.indexAssignment(e, depth)
+	case *IfExpression:
+		return f.ifExpression(e, depth)
+	case *FunctionLiteral:
+		params := make([]string, len(e.Parameters))
+		for i, param := range e.Parameters {
+			params[i] = param.Value
+		}
+		return "fn (" + strings.Join(params, ", ") + ") " + f.block(e.Body, depth)
+	case *CallExpression:
+		return f.operand(e.Function, fmtCall, depth) + "(" + f.list(e.Arguments, depth) + ")"
+	case *ArrayLiteral:
+		return "[" + f.list(e.Elements, depth) + "]"
+	case *HashLiteral:
+		pairs := make([]string, len(e.Pairs))
+		for i, pair := range e.Pairs {
+			pairs[i] = f.expression(pair.Key, depth) + ": " + f.expression(pair.Value, depth)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case *IndexExpression:
+		return f.operand(e.Left, fmtCall, depth) + "[" + f.expression(e.Index, depth) + "]"
+	default:
+		return e.String()
+	}
+}
+
+// operand renders e, in parentheses if it binds more loosely than
+// precedence.
+func (f *formatter) operand(e Expression, precedence, depth int) string {
+	if expressionPrecedence(e) < precedence {
+		return "(" + f.expression(e, depth) + ")"
+	}
+	return f.expression(e, depth)
+}
+
+func expressionPrecedence(e Expression) int {
+	switch e := e.(type) {
+	case *InfixExpression:
+		return fmtPrecedences[e.Operator]
+	case *TernaryExpression:
+		return fmtTernary
+	case *PrefixExpression, *AssignStatement, *IndexAssignStatement:
+		return fmtPrefix
+	case *IfExpression:
+		return fmtLowest
+	default:
+		return fmtCall
+	}
+}
+
+// assignment renders an assignment, or the ++ or -- it was desugared from,
+// which is the only form of assignment allowed within an expression.
+func (f *formatter) assignment(as *AssignStatement, depth int) string {
+	if as.Token.Type == token.INCREMENT || as.Token.Type == token.DECREMENT {
+		return as.Token.Literal + as.Name.Value
+	}
+	return as.Name.Value + " = " + f.expression(as.Value, depth)
+}
+
+// indexAssignment renders an index assignment, or the ++ or -- it was
+// desugared from.
+func (f *formatter) indexAssignment(ias *IndexAssignStatement, depth int) string {
+	target := f.expression(ias.Target, depth)
+	if ias.Token.Type == token.INCREMENT || ias.Token.Type == token.DECREMENT {
+		return ias.Token.Literal + target
+	}
+	return target + " = " + f.expression(ias.Value, depth)
+}
+
+// ifExpression renders an if expression, with an else block holding only
+// another if expression as else if.
+func (f *formatter) ifExpression(ie *IfExpression, depth int) string {
+	out := "if (" + f.expression(ie.Condition, depth) + ") " + f.block(ie.Consequence, depth)
+	if ie.Alternative == nil {
+		return out
+	}
+
+	if ie.Alternative.Token.Type == token.IF && len(ie.Alternative.Statements) == 1 {
+		if stmt, ok := ie.Alternative.Statements[0].(*ExpressionStatement); ok {
+			if elseIf, ok := stmt.Expression.(*IfExpression); ok {
+				return out + " else " + f.ifExpression(elseIf, depth)
+			}
+		}
+	}
+	return out + " else " + f.block(ie.Alternative, depth)
+}
+
+func (f *formatter) interpolatedString(is *InterpolatedString, depth int) string {
+	var out strings.Builder
+	out.WriteString(`f"`)
+	for _, part := range is.Parts {
+		if text, ok := part.(*StringLiteral); ok && text.Token.Type == token.STRING_PART {
+			escaped := quote(text.Value)
+			out.WriteString(strings.ReplaceAll(escaped[1:len(escaped)-1], "$", `\$`))
+			continue
+		}
+		out.WriteString("${")
+		out.WriteString(f.expression(part, depth))
+		out.WriteString("}")
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
+
+func (f *formatter) list(exps []Expression, depth int) string {
+	list := make([]string, len(exps))
+	for i, exp := range exps {
+		list[i] = f.expression(exp, depth)
+	}
+	return strings.Join(list, ", ")
+}
+
+// quote renders s as a string literal, escaping what the lexer unescapes.
+func quote(s string) string {
+	var out strings.Builder
+	out.WriteString(`"`)
+	for _, r := range s {
+		switch r {
+		case '\n':
+			out.WriteString(`\n`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\\':
+			out.WriteString(`\\`)
+		case '"':
+			out.WriteString(`\"`)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteString(`"`)
+	return out.String()
+}
@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"monkey/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PrintTree writes the tree rooted at node to w, one node per line, with each
+// node indented one level deeper than its parent. The root is indented by
+// indent levels.
+//
+//	LetStatement
+//	    Identifier x
+//	    InfixExpression +
+//	        IntegerLiteral 1
+//	        IntegerLiteral 2
+func PrintTree(node Node, w io.Writer, indent int) {
+	Walk(&treePrinter{w: w, depth: indent}, node)
+}
+
+type treePrinter struct {
+	w     io.Writer
+	depth int
+}
+
+func (tp *treePrinter) Visit(node Node) Visitor {
+	fmt.Fprintf(tp.w, "%s%s\n", strings.Repeat(indentUnit, tp.depth), nodeLabel(node))
+	return &treePrinter{w: tp.w, depth: tp.depth + 1}
+}
+
+// nodeLabel is the type of node, followed by what tells it apart from other
+// nodes of that type where its children do not, e.g. the operator of an infix
+// expression.
+func nodeLabel(node Node) string {
+	label := reflect.TypeOf(node).Elem().Name()
+
+	switch node := node.(type) {
+	case *Identifier:
+		label += " " + node.Value
+	case *IntegerLiteral, *FloatLiteral, *BoolLiteral:
+		label += " " + node.String()
+	case *StringLiteral:
+		label += " " + strconv.Quote(node.Value)
+	case *PrefixExpression:
+		label += " " + node.Operator
+	case *InfixExpression:
+		label += " " + node.Operator
+	case *CompoundAssignStatement:
+		label += " " + node.Operator
+	}
+
+	return label
+}
+
+// JSON encodes the tree rooted at node as JSON. Each node is an object with
+// its type under "type" and its fields, except for tokens, positions and
+// comments, under their names.
+func JSON(node Node) ([]byte, error) {
+	return json.MarshalIndent(jsonValue(reflect.ValueOf(node)), "", "  ")
+}
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+
+	// fields that are about the source, not the tree
+	sourceTypes = map[reflect.Type]bool{
+		tokenType:                         true,
+		reflect.TypeOf(token.Position{}):  true,
+		reflect.TypeOf([]token.Comment{}): true,
+	}
+)
+
+// jsonValue converts v, a node or a part of one, into a value that
+// encoding/json can marshal.
+func jsonValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(nodeType) && v.Kind() == reflect.Pointer {
+			obj := jsonValue(v.Elem()).(map[string]interface{})
+			obj["type"] = v.Elem().Type().Name()
+			return obj
+		}
+		return jsonValue(v.Elem())
+	case reflect.Struct:
+		obj := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || sourceTypes[field.Type] {
+				continue
+			}
+			obj[field.Name] = jsonValue(v.Field(i))
+		}
+		return obj
+	case reflect.Slice:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
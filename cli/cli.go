@@ -0,0 +1,332 @@
+// Package cli implements the monkey command.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/repl"
+	"monkey/token"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Run runs the monkey command with args, which do not include the program
+// name, and returns its exit code.
+//
+//	monkey                       start the REPL
+//	monkey file.mk               run file.mk
+//	monkey -e code               evaluate code and print its value
+//	monkey --ast file.mk         print the AST of file.mk as a tree
+//	monkey --ast-json file.mk    print the AST of file.mk as JSON
+//	monkey --tokens file.mk      print the tokens of file.mk
+//	monkey fmt [-w] file.mk...   format files, see runFmt
+//	monkey check file.mk...      check files for syntax errors
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "fmt" {
+		return runFmt(args[1:], stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "check" {
+		return runCheck(args[1:], stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("monkey", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var code string
+	flags.StringVar(&code, "e", "", "evaluate `code` and print its value")
+	flags.StringVar(&code, "eval", "", "evaluate `code` and print its value")
+	dumpAST := flags.Bool("ast", false, "print the AST of a file as a tree, without evaluating it")
+	dumpJSON := flags.Bool("ast-json", false, "print the AST of a file as JSON, without evaluating it")
+	dumpTokens := flags.Bool("tokens", false, "print the tokens of a file, one per line")
+
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+
+	if code == "" && flags.NArg() == 0 {
+		startREPL(stdin, stdout)
+		return 0
+	}
+	if code == "" && flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "expected a single file")
+		return 2
+	}
+
+	var err error
+	switch path := flags.Arg(0); {
+	case code != "":
+		err = evalCode(code, stdout)
+	case *dumpAST || *dumpJSON:
+		err = printAST(path, *dumpJSON, stdout)
+	case *dumpTokens:
+		err = printTokens(path, stdout)
+	default:
+		err = runFile(path, stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runFmt formats the files named in args, printing the result, or with -w
+// writing it back to the files. Files that fail to parse are left alone.
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("monkey fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result to the file instead of printing it")
+
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "expected files to format")
+		return 2
+	}
+
+	code := 0
+	for _, path := range flags.Args() {
+		if err := formatFile(path, *write, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+func formatFile(path string, write bool, out io.Writer) error {
+	program, err := parseFile(path)
+	if err != nil {
+		return err
+	}
+
+	formatted := ast.Format(program)
+	if !commentsKept(program.Comments, formatted) {
+		return fmt.Errorf("%s: formatting would lose comments", path)
+	}
+	if write {
+		return os.WriteFile(path, []byte(formatted), 0o644)
+	}
+	_, err = io.WriteString(out, formatted)
+	return err
+}
+
+// commentsKept reports whether formatted has all the comments, so that
+// formatting never deletes any.
+func commentsKept(comments []token.Comment, formatted string) bool {
+	l := lexer.New(formatted)
+	for l.NextToken().Type != token.EOF {
+	}
+
+	kept := l.Comments()
+	if len(kept) != len(comments) {
+		return false
+	}
+	for i := range comments {
+		if kept[i].Text != comments[i].Text {
+			return false
+		}
+	}
+	return true
+}
+
+// runCheck parses the files named in args, without evaluating them. Each file
+// that parses is reported to stdout, and each error to stderr, followed by
+// the line it is on with a caret under where it is.
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "expected files to check")
+		return 2
+	}
+
+	code := 0
+	for _, path := range args {
+		if !checkFile(path, stdout, stderr) {
+			code = 1
+		}
+	}
+	return code
+}
+
+func checkFile(path string, stdout, stderr io.Writer) bool {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return false
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		fmt.Fprintf(stdout, "OK: %s (%d statements)\n", path, len(program.Statements))
+		return true
+	}
+
+	lines := strings.Split(string(source), "\n")
+	for _, err := range p.Errors() {
+		if err.Pos == (token.Position{}) {
+			fmt.Fprintf(stderr, "%s: %s\n", path, err.Message)
+			continue
+		}
+
+		fmt.Fprintf(stderr, "%s:%s\n", path, err)
+		if err.Pos.Line <= len(lines) {
+			line := lines[err.Pos.Line-1]
+			fmt.Fprintf(stderr, "    %s\n    %s^\n", line, caretIndent(line, err.Pos.Col))
+		}
+	}
+	return false
+}
+
+// caretIndent is the whitespace that lines a caret up under column col of
+// line, keeping its tabs so that they are as wide as in the line.
+func caretIndent(line string, col int) string {
+	var indent strings.Builder
+	for i := 0; i < col-1 && i < len(line); i++ {
+		if line[i] == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	return indent.String()
+}
+
+// evalCode evaluates code and prints its value, unless it is null, e.g. the
+// value of puts.
+func evalCode(code string, out io.Writer) error {
+	program, err := parse(code)
+	if err != nil {
+		return err
+	}
+
+	result, err := eval(program, ".", out)
+	if err != nil {
+		return err
+	}
+
+	if result != nil && result != object.NULL {
+		fmt.Fprintln(out, result.Inspect())
+	}
+	return nil
+}
+
+// runFile evaluates the file at path, with builtins writing to out. Modules
+// are imported from the directory the file is in.
+func runFile(path string, out io.Writer) error {
+	program, err := parseFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = eval(program, filepath.Dir(path), out)
+	return err
+}
+
+// printAST prints the AST of the file at path, as a tree or as JSON.
+func printAST(path string, asJSON bool, out io.Writer) error {
+	program, err := parseFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !asJSON {
+		ast.PrintTree(program, out, 0)
+		return nil
+	}
+
+	data, err := ast.JSON(program)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+func printTokens(path string, out io.Writer) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lexer.DumpTokens(lexer.New(string(source)), out)
+	return nil
+}
+
+// parseFile parses the file at path. Parse errors are reported as
+// file:line:col: message, one per line.
+func parseFile(path string) (*ast.Program, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		errs := make([]error, len(p.Errors()))
+		for i, err := range p.Errors() {
+			if err.Pos == (token.Position{}) {
+				errs[i] = fmt.Errorf("%s: %s", path, err.Message)
+			} else {
+				errs[i] = fmt.Errorf("%s:%s", path, err)
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+	return program, nil
+}
+
+// parse parses source. Parse errors are reported as line:col: message, one
+// per line.
+func parse(source string) (*ast.Program, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return program, nil
+}
+
+// eval evaluates program, with builtins writing to out and modules imported
+// from dir. An error value ends the program, and is returned as an error.
+func eval(program *ast.Program, dir string, out io.Writer) (object.Object, error) {
+	e := evaluator.New(
+		evaluator.WithOutput(out),
+		evaluator.WithModuleLoader(&evaluator.FileModuleLoader{SearchPaths: []string{dir}}),
+	)
+
+	result := e.Eval(program, object.NewEnvironment())
+	if err, ok := result.(*object.Error); ok {
+		return nil, errors.New(err.Inspect())
+	}
+	return result, nil
+}
+
+func startREPL(stdin io.Reader, stdout io.Writer) {
+	user, err := user.Current()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Fprintf(stdout, "Hello %s!\n", user.Username)
+	fmt.Fprintf(stdout, "Type in some Monkeylang commands\n")
+
+	repl.Start(stdin, stdout)
+}
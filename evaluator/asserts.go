@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"testing"
+
+	"monkey/object"
+)
+
+// Assertion helpers
+// -----------------------------------------------------------------------------
+
+func assertIntegerObject(t *testing.T, obj object.Object, want int64) {
+	t.Helper()
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("object is not Integer, got=%T (%+v)", obj, obj)
+	}
+	if result.Value != want {
+		t.Errorf("object has wrong value, got=%d, want=%d", result.Value, want)
+	}
+}
+
+func assertBooleanObject(t *testing.T, obj object.Object, want bool) {
+	t.Helper()
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Fatalf("object is not Boolean, got=%T (%+v)", obj, obj)
+	}
+	if result.Value != want {
+		t.Errorf("object has wrong value, got=%t, want=%t", result.Value, want)
+	}
+}
+
+func assertErrorObject(t *testing.T, obj object.Object, want string) {
+	t.Helper()
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error, got=%T (%+v)", obj, obj)
+	}
+	if err.Message != want {
+		t.Errorf("wrong error message, got=%q, want=%q", err.Message, want)
+	}
+}
+
+func assertNullObject(t *testing.T, obj object.Object) {
+	t.Helper()
+	if obj != object.NULL {
+		t.Errorf("object is not NULL, got=%T (%+v)", obj, obj)
+	}
+}
+
+func assertStringObject(t *testing.T, obj object.Object, want string) {
+	t.Helper()
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String, got=%T (%+v)", obj, obj)
+	}
+	if result.Value != want {
+		t.Errorf("object has wrong value, got=%q, want=%q", result.Value, want)
+	}
+}
+
+func assertFloatObject(t *testing.T, obj object.Object, want float64) {
+	t.Helper()
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Fatalf("object is not Float, got=%T (%+v)", obj, obj)
+	}
+	if result.Value != want {
+		t.Errorf("object has wrong value, got=%g, want=%g", result.Value, want)
+	}
+}
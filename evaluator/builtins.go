@@ -0,0 +1,481 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"monkey/object"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// newBuiltins creates the functions every program can call without defining
+// them. A binding of the same name shadows them.
+func (e *Evaluator) newBuiltins() map[string]*object.Builtin {
+	return map[string]*object.Builtin{
+		"len":   {Fn: builtinLen},
+		"first": {Fn: builtinFirst},
+		"last":  {Fn: builtinLast},
+		"rest":  {Fn: builtinRest},
+		"push":  {Fn: builtinPush},
+		"type":  {Fn: builtinType},
+		"int":   {Fn: builtinInt},
+		"str":   {Fn: builtinStr},
+		"bool":  {Fn: builtinBool},
+		"abs":   {Fn: builtinAbs},
+		"sqrt":  {Fn: builtinSqrt},
+		"floor": {Fn: builtinFloor},
+		"ceil":  {Fn: builtinCeil},
+		"max":   {Fn: builtinMax},
+		"min":   {Fn: builtinMin},
+
+		"map":     {Fn: e.builtinMap},
+		"filter":  {Fn: e.builtinFilter},
+		"reduce":  {Fn: e.builtinReduce},
+		"memoize": {Fn: e.builtinMemoize},
+		"puts":    {Fn: e.builtinPuts},
+		"print":   {Fn: e.builtinPrint},
+	}
+}
+
+// len(x) is the number of characters in a string or elements in an array.
+func builtinLen(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	default:
+		return newError("argument to `len` not supported, got %s", arg.Type())
+	}
+}
+
+// first(arr) is the first element of arr, or null when it is empty.
+func builtinFirst(args ...object.Object) object.Object {
+	arr, err := arrayArgument("first", 1, args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return object.NULL
+	}
+	return arr.Elements[0]
+}
+
+// last(arr) is the last element of arr, or null when it is empty.
+func builtinLast(args ...object.Object) object.Object {
+	arr, err := arrayArgument("last", 1, args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return object.NULL
+	}
+	return arr.Elements[len(arr.Elements)-1]
+}
+
+// rest(arr) is a new array of all but the first element of arr, or null when
+// that would leave nothing.
+func builtinRest(args ...object.Object) object.Object {
+	arr, err := arrayArgument("rest", 1, args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) <= 1 {
+		return object.NULL
+	}
+
+	elements := make([]object.Object, len(arr.Elements)-1)
+	copy(elements, arr.Elements[1:])
+	return &object.Array{Elements: elements}
+}
+
+// push(arr, val) is a new array of the elements of arr followed by val. arr
+// itself is left unchanged.
+func builtinPush(args ...object.Object) object.Object {
+	arr, err := arrayArgument("push", 2, args)
+	if err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, len(arr.Elements), len(arr.Elements)+1)
+	copy(elements, arr.Elements)
+	return &object.Array{Elements: append(elements, args[1])}
+}
+
+// typeNames are the names type() gives to object types.
+var typeNames = map[object.ObjectType]string{
+	object.INTEGER_OBJ:  "integer",
+	object.FLOAT_OBJ:    "float",
+	object.BOOLEAN_OBJ:  "boolean",
+	object.STRING_OBJ:   "string",
+	object.ARRAY_OBJ:    "array",
+	object.HASH_OBJ:     "hash",
+	object.FUNCTION_OBJ: "function",
+	object.BUILTIN_OBJ:  "function",
+	object.NULL_OBJ:     "null",
+}
+
+// type(x) is the name of the type of x, e.g. "integer".
+func builtinType(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	name, ok := typeNames[args[0].Type()]
+	if !ok {
+		name = strings.ToLower(string(args[0].Type()))
+	}
+	return &object.String{Value: name}
+}
+
+// int(x) converts an integer or a decimal string to an integer.
+func builtinInt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg
+	case *object.Float:
+		return floatToInteger("int", math.Trunc(arg.Value))
+	case *object.String:
+		value, err := strconv.ParseInt(arg.Value, 10, 64)
+		if err != nil {
+			return newError("could not convert %q to integer", arg.Value)
+		}
+		return &object.Integer{Value: value}
+	default:
+		return newError("argument to `int` not supported, got %s", arg.Type())
+	}
+}
+
+// str(x) converts any value to a string, as puts would write it.
+func builtinStr(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	if str, ok := args[0].(*object.String); ok {
+		return str
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// bool(x) converts x to a boolean. Unlike in conditions, zero and empty
+// values convert to false.
+func builtinBool(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Boolean:
+		return arg
+	case *object.Null:
+		return object.FALSE
+	case *object.Integer:
+		return nativeBoolToBooleanObject(arg.Value != 0)
+	case *object.String:
+		return nativeBoolToBooleanObject(arg.Value != "")
+	case *object.Array:
+		return nativeBoolToBooleanObject(len(arg.Elements) != 0)
+	case *object.Hash:
+		return nativeBoolToBooleanObject(len(arg.Pairs) != 0)
+	default:
+		return object.TRUE
+	}
+}
+
+// abs(n) is the absolute value of n, of the same type as n.
+func builtinAbs(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value < 0 {
+			return &object.Integer{Value: -arg.Value}
+		}
+		return arg
+	case *object.Float:
+		return object.NewFloat(math.Abs(arg.Value))
+	default:
+		return newError("argument to `abs` must be a number, got %s", arg.Type())
+	}
+}
+
+// sqrt(n) is the square root of n, as a float.
+func builtinSqrt(args ...object.Object) object.Object {
+	n, err := numberArgument("sqrt", args)
+	if err != nil {
+		return err
+	}
+
+	if n < 0 {
+		return newError("square root of negative number: %s", args[0].Inspect())
+	}
+	return object.NewFloat(math.Sqrt(n))
+}
+
+// floor(n) is the greatest integer less than or equal to n.
+func builtinFloor(args ...object.Object) object.Object {
+	n, err := numberArgument("floor", args)
+	if err != nil {
+		return err
+	}
+	if integer, ok := args[0].(*object.Integer); ok {
+		return integer
+	}
+	return floatToInteger("floor", math.Floor(n))
+}
+
+// ceil(n) is the least integer greater than or equal to n.
+func builtinCeil(args ...object.Object) object.Object {
+	n, err := numberArgument("ceil", args)
+	if err != nil {
+		return err
+	}
+	if integer, ok := args[0].(*object.Integer); ok {
+		return integer
+	}
+	return floatToInteger("ceil", math.Ceil(n))
+}
+
+// max(a, b, ...) is the greatest of its numeric arguments, which are compared
+// as floats but returned unchanged.
+func builtinMax(args ...object.Object) object.Object {
+	return extremum("max", args, func(a, b float64) bool { return a > b })
+}
+
+// min(a, b, ...) is the least of its numeric arguments.
+func builtinMin(args ...object.Object) object.Object {
+	return extremum("min", args, func(a, b float64) bool { return a < b })
+}
+
+// extremum returns the first of args for which better is true compared to all
+// the others.
+func extremum(name string, args []object.Object, better func(a, b float64) bool) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments: want at least 1, got=0")
+	}
+
+	var best object.Object
+	var bestValue float64
+	for _, arg := range args {
+		value, ok := toFloat64(arg)
+		if !ok {
+			return newError("arguments to `%s` must be numbers, got %s", name, arg.Type())
+		}
+		if best == nil || better(value, bestValue) {
+			best, bestValue = arg, value
+		}
+	}
+	return best
+}
+
+// numberArgument checks that the builtin called name got a single numeric
+// argument, and returns its value.
+func numberArgument(name string, args []object.Object) (float64, *object.Error) {
+	if len(args) != 1 {
+		return 0, wrongNumberOfArguments(1, len(args))
+	}
+
+	value, ok := toFloat64(args[0])
+	if !ok {
+		return 0, newError("argument to `%s` must be a number, got %s", name, args[0].Type())
+	}
+	return value, nil
+}
+
+// floatToInteger converts f, a whole number, to an integer, or reports that
+// the argument to the builtin name is out of range if it is NaN or not within
+// [-2^63, 2^63).
+func floatToInteger(name string, f float64) object.Object {
+	if math.IsNaN(f) || f < -(1<<63) || f >= 1<<63 {
+		return newError("argument to `%s` out of integer range", name)
+	}
+	return &object.Integer{Value: int64(f)}
+}
+
+func toFloat64(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// map(arr, fn) is a new array of fn applied to each element of arr.
+func (e *Evaluator) builtinMap(args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArguments("map", args, 2)
+	if err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, 0, len(arr.Elements))
+	for _, el := range arr.Elements {
+		result := e.applyFunction(fn, []object.Object{el})
+		if isError(result) {
+			return result
+		}
+		elements = append(elements, result)
+	}
+	return &object.Array{Elements: elements}
+}
+
+// filter(arr, fn) is a new array of the elements of arr for which fn is
+// truthy.
+func (e *Evaluator) builtinFilter(args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArguments("filter", args, 2)
+	if err != nil {
+		return err
+	}
+
+	elements := []object.Object{}
+	for _, el := range arr.Elements {
+		result := e.applyFunction(fn, []object.Object{el})
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			elements = append(elements, el)
+		}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// reduce(arr, initial, fn) folds arr from the left, starting from initial:
+// fn(fn(initial, arr[0]), arr[1]) and so on.
+func (e *Evaluator) builtinReduce(args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArguments("reduce", args, 3)
+	if err != nil {
+		return err
+	}
+
+	acc := args[1]
+	for _, el := range arr.Elements {
+		acc = e.applyFunction(fn, []object.Object{acc, el})
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+// memoize(fn) is a function that calls fn, but only once for the same
+// arguments: the result is cached by their hash keys and returned again after
+// that. Calls with an argument that is not hashable, and calls that fail,
+// are not cached.
+func (e *Evaluator) builtinMemoize(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(1, len(args))
+	}
+
+	fn := args[0]
+	switch fn.(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return newError("argument to `memoize` must be FUNCTION, got %s", fn.Type())
+	}
+
+	cache := make(map[string]object.Object)
+	memoized := func(args ...object.Object) object.Object {
+		key, ok := memoKey(args)
+		if !ok {
+			return e.applyFunction(fn, args)
+		}
+		if result, ok := cache[key]; ok {
+			return result
+		}
+
+		result := e.applyFunction(fn, args)
+		if !isError(result) {
+			cache[key] = result
+		}
+		return result
+	}
+	return &object.Builtin{Fn: memoized}
+}
+
+// memoKey joins the hash keys of args into one cache key, or reports false if
+// any of them is not hashable.
+func memoKey(args []object.Object) (string, bool) {
+	var key strings.Builder
+	for _, arg := range args {
+		hashable, ok := arg.(object.Hashable)
+		if !ok {
+			return "", false
+		}
+		hashKey := hashable.HashKey()
+		fmt.Fprintf(&key, "%s:%d;", hashKey.Type, hashKey.Value)
+	}
+	return key.String(), true
+}
+
+// arrayAndFunctionArguments checks that the builtin called name got want args,
+// the first of which is an array and the last a function.
+func arrayAndFunctionArguments(name string, args []object.Object, want int) (*object.Array, object.Object, *object.Error) {
+	arr, err := arrayArgument(name, want, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fn := args[want-1]
+	switch fn.(type) {
+	case *object.Function, *object.Builtin:
+		return arr, fn, nil
+	default:
+		return nil, nil, newError("argument to `%s` must be FUNCTION, got %s", name, fn.Type())
+	}
+}
+
+// puts(args...) writes each argument on its own line.
+func (e *Evaluator) builtinPuts(args ...object.Object) object.Object {
+	for _, arg := range args {
+		fmt.Fprintln(e.out, arg.Inspect())
+	}
+	return object.NULL
+}
+
+// print(args...) writes the arguments separated by spaces, without ending the
+// line.
+func (e *Evaluator) builtinPrint(args ...object.Object) object.Object {
+	for i, arg := range args {
+		if i > 0 {
+			fmt.Fprint(e.out, " ")
+		}
+		fmt.Fprint(e.out, arg.Inspect())
+	}
+	return object.NULL
+}
+
+// arrayArgument checks that the builtin called name got want args, the first
+// of which is an array.
+func arrayArgument(name string, want int, args []object.Object) (*object.Array, *object.Error) {
+	if len(args) != want {
+		return nil, wrongNumberOfArguments(want, len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
+
+func wrongNumberOfArguments(want, got int) *object.Error {
+	return newError("wrong number of arguments: want=%d, got=%d", want, got)
+}
@@ -0,0 +1,305 @@
+package evaluator
+
+import (
+	"monkey/test"
+	"testing"
+)
+
+func TestBuiltinLen(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`let len = fn(x) { 42 }; len("a")`, 42},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments: want=1, got=2"},
+		{`len()`, "wrong number of arguments: want=1, got=0"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			assertErrorObject(t, got, want)
+		}
+	}
+}
+
+func TestBuiltinPuts(t *testing.T) {
+	got, out := testEvalOutput(t, `puts("hello", 42, [1, 2]); puts(); puts(true)`)
+
+	assertNullObject(t, got)
+	test.AssertEqual(t, out, "hello\n42\n[1, 2]\ntrue\n")
+}
+
+func TestBuiltinPrint(t *testing.T) {
+	got, out := testEvalOutput(t, `print("a", 1, null); print("b")`)
+
+	assertNullObject(t, got)
+	test.AssertEqual(t, out, "a 1 nullb")
+}
+
+type builtinTest struct {
+	input string
+	want  interface{}
+}
+
+// assertBuiltinResults checks results that are either an integer, an array
+// rendered by Inspect, an error or null.
+func assertBuiltinResults(t *testing.T, tests []builtinTest) {
+	t.Helper()
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			test.AssertEqual(t, got.Inspect(), want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		default:
+			assertNullObject(t, got)
+		}
+	}
+}
+
+func TestBuiltinFirstLast(t *testing.T) {
+	assertBuiltinResults(t, []builtinTest{
+		{`first([])`, nil},
+		{`first([1])`, 1},
+		{`first([1, 2, 3])`, 1},
+		{`last([])`, nil},
+		{`last([1])`, 1},
+		{`last([1, 2, 3])`, 3},
+		{`first(1)`, errorMessage("argument to `first` must be ARRAY, got INTEGER")},
+		{`last("abc")`, errorMessage("argument to `last` must be ARRAY, got STRING")},
+		{`first([1], [2])`, errorMessage("wrong number of arguments: want=1, got=2")},
+		{`last()`, errorMessage("wrong number of arguments: want=1, got=0")},
+	})
+}
+
+func TestBuiltinRest(t *testing.T) {
+	assertBuiltinResults(t, []builtinTest{
+		{`rest([])`, nil},
+		{`rest([1])`, nil},
+		{`rest([1, 2])`, "[2]"},
+		{`rest([1, 2, 3])`, "[2, 3]"},
+		{`rest(rest([1, 2, 3]))`, "[3]"},
+		{`let a = [1, 2, 3]; rest(a); a`, "[1, 2, 3]"},
+		{`rest(1)`, errorMessage("argument to `rest` must be ARRAY, got INTEGER")},
+	})
+}
+
+func TestBuiltinPush(t *testing.T) {
+	assertBuiltinResults(t, []builtinTest{
+		{`push([], 1)`, "[1]"},
+		{`push([1], 2)`, "[1, 2]"},
+		{`push([1, 2], [3])`, "[1, 2, [3]]"},
+		{`let a = [1]; push(a, 2); a`, "[1]"},
+		{`let a = [1]; let b = push(a, 2); let c = push(a, 3); b`, "[1, 2]"},
+		{`push(1, 1)`, errorMessage("argument to `push` must be ARRAY, got INTEGER")},
+		{`push([1])`, errorMessage("wrong number of arguments: want=2, got=1")},
+	})
+}
+
+func TestBuiltinType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`type(5)`, "integer"},
+		{`type(0.5)`, "float"},
+		{`type(true)`, "boolean"},
+		{`type("hi")`, "string"},
+		{`type([])`, "array"},
+		{`type({})`, "hash"},
+		{`type(null)`, "null"},
+		{`type(fn() {})`, "function"},
+		{`type(len)`, "function"},
+		{`type(5) == "integer"`, true},
+		{`if (type("x") == "integer") { 1 } else { 2 }`, 2},
+		{`type()`, errorMessage("wrong number of arguments: want=1, got=0")},
+		{`type(1, 2)`, errorMessage("wrong number of arguments: want=1, got=2")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case string:
+			assertStringObject(t, got, want)
+		case bool:
+			assertBooleanObject(t, got, want)
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestBuiltinIntConversion(t *testing.T) {
+	assertBuiltinResults(t, []builtinTest{
+		{`int("42")`, 42},
+		{`int("-7")`, -7},
+		{`int(5)`, 5},
+		{`int(3.14)`, 3},
+		{`int(-2.9)`, -2},
+		{`int(-9223372036854775808.0)`, -9223372036854775808},
+		{`int(100000000000000000000000000000.0)`, errorMessage("argument to `int` out of integer range")},
+		{`int(9223372036854775808.0)`, errorMessage("argument to `int` out of integer range")},
+		{`int(-(10.0 ** 400))`, errorMessage("argument to `int` out of integer range")},
+		{`let inf = 10.0 ** 400; int(inf - inf)`, errorMessage("argument to `int` out of integer range")},
+		{`int("42") + 1`, 43},
+		{`int("abc")`, errorMessage(`could not convert "abc" to integer`)},
+		{`int("4.2")`, errorMessage(`could not convert "4.2" to integer`)},
+		{`int("")`, errorMessage(`could not convert "" to integer`)},
+		{`int(true)`, errorMessage("argument to `int` not supported, got BOOLEAN")},
+		{`int()`, errorMessage("wrong number of arguments: want=1, got=0")},
+	})
+}
+
+func TestBuiltinStrConversion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`str(42)`, "42"},
+		{`str(-1)`, "-1"},
+		{`str(true)`, "true"},
+		{`str(null)`, "null"},
+		{`str("hi")`, "hi"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`"n=" + str(1 + 1)`, "n=2"},
+	}
+
+	for _, tt := range tests {
+		assertStringObject(t, testEval(t, tt.input), tt.want)
+	}
+
+	assertErrorObject(t, testEval(t, `str(1, 2)`), "wrong number of arguments: want=1, got=2")
+}
+
+func TestBuiltinBoolConversion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`bool(0)`, false},
+		{`bool(1)`, true},
+		{`bool(-1)`, true},
+		{`bool("")`, false},
+		{`bool("any")`, true},
+		{`bool(true)`, true},
+		{`bool(false)`, false},
+		{`bool(null)`, false},
+		{`bool([])`, false},
+		{`bool([0])`, true},
+		{`bool({})`, false},
+		{`bool(fn() {})`, true},
+	}
+
+	for _, tt := range tests {
+		assertBooleanObject(t, testEval(t, tt.input), tt.want)
+	}
+
+	assertErrorObject(t, testEval(t, `bool()`), "wrong number of arguments: want=1, got=0")
+}
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`abs(5)`, 5},
+		{`abs(-5)`, 5},
+		{`abs(0)`, 0},
+		{`abs(-2.5)`, 2.5},
+		{`sqrt(16)`, 4.0},
+		{`sqrt(2.25)`, 1.5},
+		{`sqrt(0)`, 0.0},
+		{`floor(2.7)`, 2},
+		{`floor(-2.5)`, -3},
+		{`floor(4)`, 4},
+		{`ceil(2.1)`, 3},
+		{`ceil(-2.5)`, -2},
+		{`ceil(4)`, 4},
+		{`floor(9223372036854775807)`, 9223372036854775807},
+		{`floor(100000000000000000000000000000.5)`, errorMessage("argument to `floor` out of integer range")},
+		{`ceil(10.0 ** 400)`, errorMessage("argument to `ceil` out of integer range")},
+		{`ceil(-(10.0 ** 400))`, errorMessage("argument to `ceil` out of integer range")},
+		{`max(1)`, 1},
+		{`max(1, 3, 2)`, 3},
+		{`max(1, 2.5)`, 2.5},
+		{`max(3, 2.5)`, 3},
+		{`min(1, 3, 2)`, 1},
+		{`min(-1.5, 0, 2)`, -1.5},
+		{`min(2, 2.0)`, 2},
+		{`abs("a")`, errorMessage("argument to `abs` must be a number, got STRING")},
+		{`sqrt(-1)`, errorMessage("square root of negative number: -1")},
+		{`sqrt(true)`, errorMessage("argument to `sqrt` must be a number, got BOOLEAN")},
+		{`floor("1.5")`, errorMessage("argument to `floor` must be a number, got STRING")},
+		{`ceil()`, errorMessage("wrong number of arguments: want=1, got=0")},
+		{`max()`, errorMessage("wrong number of arguments: want at least 1, got=0")},
+		{`min(1, "2")`, errorMessage("arguments to `min` must be numbers, got STRING")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case float64:
+			assertFloatObject(t, got, want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestBuiltinMemoize(t *testing.T) {
+	fib := `let calls = 0;
+let fib = memoize(fn(n) { calls += 1; if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } });
+`
+	assertBuiltinResults(t, []builtinTest{
+		{fib + `fib(30)`, 832040},
+		// each fib(n) is computed only once
+		{fib + `fib(30); fib(30); calls`, 31},
+		{`let calls = 0; let f = memoize(fn(a, b) { calls += 1; a + b }); f(1, 2); f(2, 1); f(1, 2); calls`, 2},
+		{`let calls = 0; let f = memoize(fn(s) { calls += 1; len(s) }); f("ab") + f("ab") + calls`, 5},
+		// unhashable arguments call straight through
+		{`let calls = 0; let f = memoize(fn(a) { calls += 1; len(a) }); f([1]); f([1]); calls`, 2},
+		{`let f = memoize(fn(x) { 1 / x }); f(0)`, errorMessage("division by zero")},
+		{`memoize(len)("abc")`, 3},
+		{`memoize(1)`, errorMessage("argument to `memoize` must be FUNCTION, got INTEGER")},
+		{`memoize()`, errorMessage("wrong number of arguments: want=1, got=0")},
+	})
+}
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	assertBuiltinResults(t, []builtinTest{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map([], fn(x) { x * 2 })`, "[]"},
+		{`map(["a", "bc"], len)`, "[1, 2]"},
+		{`let a = [1, 2]; map(a, fn(x) { x + 1 }); a`, "[1, 2]"},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, "[3, 4]"},
+		{`filter([1, 2, 3], fn(x) { false })`, "[]"},
+		{`filter([0, null, false, ""], fn(x) { x })`, "[0, ]"},
+		{`reduce([1, 2, 3], 0, fn(a, b) { a + b })`, 6},
+		{`reduce([], 10, fn(a, b) { a + b })`, 10},
+		{`reduce(["a", "b"], "", fn(acc, s) { acc + s })`, "ab"},
+		{`let n = 10; map([1], fn(x) { x + n })`, "[11]"},
+		{`map(1, fn(x) { x })`, errorMessage("argument to `map` must be ARRAY, got INTEGER")},
+		{`filter([1], 1)`, errorMessage("argument to `filter` must be FUNCTION, got INTEGER")},
+		{`reduce([1], fn(a, b) { a })`, errorMessage("wrong number of arguments: want=3, got=2")},
+		{`map([1], fn(a, b) { a })`, errorMessage("wrong number of arguments: want=2, got=1")},
+		{`map([1, 0], fn(x) { 1 / x })`, errorMessage("division by zero")},
+	})
+}
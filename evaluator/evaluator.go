@@ -0,0 +1,996 @@
+package evaluator
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"monkey/ast"
+	"monkey/object"
+	"os"
+	"strings"
+)
+
+// Evaluator evaluates Monkey programs. Its options decide how the program
+// interacts with the world, e.g. where builtins like puts write to.
+type Evaluator struct {
+	out      io.Writer
+	builtins map[string]*object.Builtin
+
+	depth    int // number of function calls in progress
+	maxDepth int
+
+	loader ModuleLoader // nil if imports are not supported
+
+	immutableClosures bool
+
+	unusedVarWarnings bool
+	warnings          []string
+}
+
+// DefaultMaxDepth is how deeply function calls can nest by default.
+const DefaultMaxDepth = 1000
+
+// Option configures an Evaluator created by New.
+type Option func(*Evaluator)
+
+// WithOutput makes builtins write to w instead of os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(e *Evaluator) {
+		e.out = w
+	}
+}
+
+// WithMaxDepth limits how deeply function calls can nest to n, instead of
+// DefaultMaxDepth. Deeper calls produce an error rather than overflowing the
+// Go stack.
+func WithMaxDepth(n int) Option {
+	return func(e *Evaluator) {
+		e.maxDepth = n
+	}
+}
+
+// WithImmutableClosures makes functions close over a copy of the environment
+// they are created in, instead of the environment itself, so that they do not
+// see the names in it rebound afterwards. A function bound by let can still
+// call itself.
+func WithImmutableClosures() Option {
+	return func(e *Evaluator) {
+		e.immutableClosures = true
+	}
+}
+
+// WithUnusedVarWarnings makes the evaluator warn about the names a program
+// binds with let at its top level but never reads, once it has run. The
+// warnings are returned by Warnings. Function parameters are not warned
+// about.
+func WithUnusedVarWarnings() Option {
+	return func(e *Evaluator) {
+		e.unusedVarWarnings = true
+	}
+}
+
+// WithModuleLoader makes import statements load modules with loader.
+func WithModuleLoader(loader ModuleLoader) Option {
+	return func(e *Evaluator) {
+		e.loader = loader
+	}
+}
+
+func New(opts ...Option) *Evaluator {
+	e := &Evaluator{out: os.Stdout, maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.builtins = e.newBuiltins()
+	return e
+}
+
+// Builtins returns the builtin functions, by name, that programs evaluated by
+// e can call.
+func (e *Evaluator) Builtins() map[string]*object.Builtin {
+	return e.builtins
+}
+
+// moduleEvaluator creates an evaluator for the modules e imports, with e's
+// options and loader. Only the importing program is warned about.
+func (e *Evaluator) moduleEvaluator(loader ModuleLoader) *Evaluator {
+	m := &Evaluator{
+		out:               e.out,
+		maxDepth:          e.maxDepth,
+		loader:            loader,
+		immutableClosures: e.immutableClosures,
+	}
+	m.builtins = m.newBuiltins()
+	return m
+}
+
+// Warnings returns the warnings about the programs e has evaluated so far,
+// e.g. "x declared but not used".
+func (e *Evaluator) Warnings() []string {
+	return e.warnings
+}
+
+// Eval evaluates node in env with an Evaluator using the default options.
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	return New().Eval(node, env)
+}
+
+// Eval evaluates node in env. Nodes that cannot be evaluated yet produce an
+// error.
+func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// statements
+	case *ast.Program:
+		return e.evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return e.Eval(node.Expression, env)
+	case *ast.BlockStatement:
+		return e.evalBlockStatement(node, env)
+	case *ast.LetStatement:
+		return e.evalLetStatement(node, env)
+	case *ast.AssignStatement:
+		return e.evalAssignStatement(node, env)
+	case *ast.CompoundAssignStatement:
+		return e.evalCompoundAssignStatement(node, env)
+	case *ast.ReturnStatement:
+		return e.evalReturnStatement(node, env)
+	case *ast.IndexAssignStatement:
+		return e.evalIndexAssignStatement(node, env)
+	case *ast.ImportStatement:
+		return e.evalImportStatement(node, env)
+	case *ast.WhileStatement:
+		return e.evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return e.evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return evalBreakStatement()
+	case *ast.ContinueStatement:
+		return evalContinueStatement()
+
+	// expressions
+	case *ast.Identifier:
+		return e.evalIdentifier(node, env)
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return object.NewFloat(node.Value)
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return e.evalInterpolatedString(node, env)
+	case *ast.ArrayLiteral:
+		elements := e.evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return e.evalHashLiteral(node, env)
+	case *ast.BoolLiteral:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return object.NULL
+	case *ast.PrefixExpression:
+		right := e.Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+	case *ast.InfixExpression:
+		switch node.Operator {
+		case "&&":
+			return e.evalLogicalAnd(node.Left, node.Right, env)
+		case "||":
+			return e.evalLogicalOr(node.Left, node.Right, env)
+		}
+		left := e.Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := e.Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalInfixExpression(node.Operator, left, right)
+	case *ast.IfExpression:
+		return e.evalIfExpression(node, env)
+	case *ast.TernaryExpression:
+		return e.evalTernaryExpression(node, env)
+	case *ast.FunctionLiteral:
+		if e.immutableClosures {
+			env = env.Copy()
+		}
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+	case *ast.CallExpression:
+		return e.evalCallExpression(node, env)
+	case *ast.IndexExpression:
+		left := e.Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := e.Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	}
+
+	return newError("cannot evaluate %T", node)
+}
+
+// evalProgram evaluates the statements of program in turn, up to a return
+// statement, whose value is the value of the program, or the first error.
+func (e *Evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	if e.unusedVarWarnings {
+		defer e.warnUnusedVars(program, env)
+	}
+
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = e.Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		case *object.BreakSignal, *object.ContinueSignal:
+			return newError("%s outside of a loop", result.Inspect())
+		}
+	}
+
+	return result
+}
+
+// warnUnusedVars adds a warning for each name bound by a top-level let
+// statement of program that was never read from env, where it was bound.
+func (e *Evaluator) warnUnusedVars(program *ast.Program, env *object.Environment) {
+	for _, statement := range program.Statements {
+		let, ok := statement.(*ast.LetStatement)
+		if ok && env.AccessCount(let.Name.Value) == 0 {
+			e.warnings = append(e.warnings, let.Name.Value+" declared but not used")
+		}
+	}
+}
+
+// evalBlockStatement evaluates the statements of block in turn, stopping at
+// the first return, break or continue statement or error. A return value or
+// loop signal is left as it is, so that the blocks enclosing this one stop too.
+func (e *Evaluator) evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object = object.NULL
+
+	for _, statement := range block.Statements {
+		result = e.Eval(statement, env)
+
+		if endsBlock(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// endsBlock reports whether obj stops the evaluation of the block it was
+// produced in.
+func endsBlock(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.ReturnValue, *object.Error, *object.BreakSignal, *object.ContinueSignal:
+		return true
+	}
+	return false
+}
+
+// evalLetStatement binds the value in env. The statement itself evaluates to
+// null.
+func (e *Evaluator) evalLetStatement(ls *ast.LetStatement, env *object.Environment) object.Object {
+	val := e.Eval(ls.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	env.Set(ls.Name.Value, val)
+	// the copy a function literal closed over was made before it was bound
+	if _, ok := ls.Value.(*ast.FunctionLiteral); ok && e.immutableClosures {
+		val.(*object.Function).Env.Set(ls.Name.Value, val)
+	}
+	return object.NULL
+}
+
+// evalAssignStatement updates an existing binding, in the scope it was made
+// in, and evaluates to the new value. Unlike let, it never binds a new name.
+func (e *Evaluator) evalAssignStatement(as *ast.AssignStatement, env *object.Environment) object.Object {
+	if _, ok := env.Get(as.Name.Value); !ok {
+		return newError("assignment to undefined variable: %s", as.Name.Value)
+	}
+
+	val := e.Eval(as.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	env.Assign(as.Name.Value, val)
+	return val
+}
+
+// evalIndexAssignStatement stores a value in an array or hash, in place, so
+// every binding of it sees the change. An array index must be in range, while
+// a hash gains the key if it did not have it.
+func (e *Evaluator) evalIndexAssignStatement(ias *ast.IndexAssignStatement, env *object.Environment) object.Object {
+	left := e.Eval(ias.Target.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := e.Eval(ias.Target.Index, env)
+	if isError(index) {
+		return index
+	}
+	val := e.Eval(ias.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return newError("index assignment not supported: %s[%s]", left.Type(), index.Type())
+		}
+		if i.Value < 0 || i.Value >= int64(len(left.Elements)) {
+			return newError("index out of range: %d", i.Value)
+		}
+		left.Elements[i.Value] = val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.Set(key.HashKey(), object.HashPair{Key: index, Value: val})
+	default:
+		return newError("index assignment not supported: %s[%s]", left.Type(), index.Type())
+	}
+	return val
+}
+
+// evalCompoundAssignStatement evaluates x += y like x = x + y, with x looked up
+// only once.
+func (e *Evaluator) evalCompoundAssignStatement(cas *ast.CompoundAssignStatement, env *object.Environment) object.Object {
+	current, ok := env.Get(cas.Name.Value)
+	if !ok {
+		return newError("assignment to undefined variable: %s", cas.Name.Value)
+	}
+
+	val := e.Eval(cas.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	operator := strings.TrimSuffix(cas.Operator, "=")
+	result := evalInfixExpression(operator, current, val)
+	if isError(result) {
+		return result
+	}
+
+	env.Assign(cas.Name.Value, result)
+	return result
+}
+
+func (e *Evaluator) evalReturnStatement(rs *ast.ReturnStatement, env *object.Environment) object.Object {
+	val := e.Eval(rs.ReturnValue, env)
+	if isError(val) {
+		return val
+	}
+	return &object.ReturnValue{Value: val}
+}
+
+// evalWhileStatement runs the body for as long as the condition is truthy. A
+// return statement or an error in the body ends the loop and is passed on; the
+// loop itself evaluates to null.
+func (e *Evaluator) evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := e.Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return object.NULL
+		}
+
+		result := e.evalBlockStatement(ws.Body, env)
+		switch result.(type) {
+		case *object.BreakSignal:
+			return object.NULL
+		case *object.ReturnValue, *object.Error:
+			return result
+		}
+	}
+}
+
+// evalForStatement runs the body like a while loop, in a scope of its own so
+// that the names bound by the init statement do not outlive the loop. A
+// missing condition is always true.
+func (e *Evaluator) evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	env = object.NewEnclosedEnvironment(env)
+
+	if fs.Init != nil {
+		if init := e.Eval(fs.Init, env); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := e.Eval(fs.Condition, env)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return object.NULL
+			}
+		}
+
+		result := e.evalBlockStatement(fs.Body, env)
+		switch result.(type) {
+		case *object.BreakSignal:
+			return object.NULL
+		case *object.ReturnValue, *object.Error:
+			return result
+		}
+
+		if fs.Post != nil {
+			if post := e.Eval(fs.Post, env); isError(post) {
+				return post
+			}
+		}
+	}
+}
+
+func evalBreakStatement() object.Object {
+	return &object.BreakSignal{}
+}
+
+func evalContinueStatement() object.Object {
+	return &object.ContinueSignal{}
+}
+
+// evalImportStatement loads a module and binds every name bound at its top
+// level in env, as if it were defined there. The statement itself evaluates to
+// null. Modules can only be imported into the top-level environment, not
+// within a function or loop scope.
+func (e *Evaluator) evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	path := is.Path.Value
+	if env.Outer() != nil {
+		return newError("cannot import %q: import is only allowed at the top level", path)
+	}
+	if e.loader == nil {
+		return newError("cannot import %q: no module loader", path)
+	}
+
+	var module *object.Module
+	var err error
+	if loader, ok := e.loader.(importerLoader); ok {
+		module, err = loader.loadFor(path, e)
+	} else {
+		module, err = e.loader.Load(path)
+	}
+	if err != nil {
+		return newError("cannot import %q: %s", path, err)
+	}
+
+	for _, name := range module.Env.Names() {
+		val, _ := module.Env.Get(name)
+		env.Set(name, val)
+	}
+	return object.NULL
+}
+
+func (e *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	if builtin, ok := e.builtins[node.Value]; ok {
+		return builtin
+	}
+	return newError("identifier not found: %s", node.Value)
+}
+
+func (e *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := e.Eval(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	switch {
+	case isTruthy(condition):
+		return e.Eval(ie.Consequence, env)
+	case ie.Alternative != nil:
+		return e.Eval(ie.Alternative, env)
+	default:
+		return object.NULL
+	}
+}
+
+// evalTernaryExpression evaluates c ? a : b like if (c) { a } else { b }.
+func (e *Evaluator) evalTernaryExpression(te *ast.TernaryExpression, env *object.Environment) object.Object {
+	condition := e.Eval(te.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return e.Eval(te.Consequence, env)
+	}
+	return e.Eval(te.Alternative, env)
+}
+
+// evalInterpolatedString joins its parts, with the value of each expression
+// rendered as puts would.
+func (e *Evaluator) evalInterpolatedString(is *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range is.Parts {
+		val := e.Eval(part, env)
+		if isError(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
+func (e *Evaluator) evalCallExpression(ce *ast.CallExpression, env *object.Environment) object.Object {
+	function := e.Eval(ce.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := e.evalExpressions(ce.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	return e.applyFunction(function, args)
+}
+
+// evalExpressions evaluates exps from left to right. On the first error, it
+// returns just that error.
+func (e *Evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	result := []object.Object{}
+
+	for _, exp := range exps {
+		evaluated := e.Eval(exp, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+// applyFunction calls fn with args. A function literal is evaluated with its
+// args bound to its parameters, in a scope enclosed by the environment it was
+// defined in.
+//
+// A call in tail position in the body is not made there, but handed back as a
+// tailCall and made here instead, so that tail recursion runs in a loop rather
+// than growing the Go stack.
+func (e *Evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
+	e.depth++
+	defer func() { e.depth-- }()
+	if e.depth > e.maxDepth {
+		return newError("maximum call depth %d exceeded", e.maxDepth)
+	}
+
+	for {
+		switch function := fn.(type) {
+		case *object.Builtin:
+			return function.Fn(args...)
+		case *object.Function:
+			if len(args) != len(function.Parameters) {
+				return wrongNumberOfArguments(len(function.Parameters), len(args))
+			}
+
+			env := object.NewEnclosedEnvironment(function.Env)
+			for i, param := range function.Parameters {
+				env.Set(param.Value, args[i])
+			}
+
+			result := e.evalTailPosition(function.Body, env)
+			if call, ok := result.(*tailCall); ok {
+				fn, args = call.fn, call.args
+				continue
+			}
+			return unwrapReturnValue(result)
+		default:
+			return newError("not a function: %s", fn.Type())
+		}
+	}
+}
+
+// tailCall is a call in tail position that is yet to be made by
+// applyFunction. It never escapes the function body it was found in.
+type tailCall struct {
+	fn   object.Object
+	args []object.Object
+}
+
+func (tc *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string         { return "tail call" }
+
+// evalTailPosition evaluates node like Eval, where the value of node is the
+// value of the enclosing function: a call whose value would be returned as is
+// is handed back as a tailCall instead of being made.
+func (e *Evaluator) evalTailPosition(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		var result object.Object = object.NULL
+		for i, statement := range node.Statements {
+			if i == len(node.Statements)-1 {
+				return e.evalTailPosition(statement, env)
+			}
+
+			result = e.Eval(statement, env)
+			if endsBlock(result) {
+				return result
+			}
+		}
+		return result
+	case *ast.ExpressionStatement:
+		return e.evalTailPosition(node.Expression, env)
+	case *ast.ReturnStatement:
+		val := e.evalTailPosition(node.ReturnValue, env)
+		if _, ok := val.(*tailCall); ok || isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.IfExpression:
+		condition := e.Eval(node.Condition, env)
+		switch {
+		case isError(condition):
+			return condition
+		case isTruthy(condition):
+			return e.evalTailPosition(node.Consequence, env)
+		case node.Alternative != nil:
+			return e.evalTailPosition(node.Alternative, env)
+		default:
+			return object.NULL
+		}
+	case *ast.TernaryExpression:
+		condition := e.Eval(node.Condition, env)
+		switch {
+		case isError(condition):
+			return condition
+		case isTruthy(condition):
+			return e.evalTailPosition(node.Consequence, env)
+		default:
+			return e.evalTailPosition(node.Alternative, env)
+		}
+	case *ast.CallExpression:
+		function := e.Eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := e.evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return &tailCall{fn: function, args: args}
+	default:
+		return e.Eval(node, env)
+	}
+}
+
+// unwrapReturnValue stops a return from a function call ending the blocks that
+// enclose the call too. A break or continue cannot end a loop outside of the
+// function either.
+func unwrapReturnValue(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.BreakSignal, *object.ContinueSignal:
+		return newError("%s outside of a loop", obj.Inspect())
+	}
+	return obj
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// evalBangOperatorExpression negates the truthiness of right: only false and
+// null are falsy.
+func evalBangOperatorExpression(right object.Object) object.Object {
+	return nativeBoolToBooleanObject(!isTruthy(right))
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return object.NewFloat(-right.Value)
+	default:
+		return newError("unknown operator: -%s", right.Type())
+	}
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left.(*object.Integer), right.(*object.Integer))
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, toFloat(left), toFloat(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left.(*object.String), right.(*object.String))
+	case operator == "==" || operator == "!=":
+		return evalBooleanInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalLogicalAnd evaluates right only if left is truthy.
+func (e *Evaluator) evalLogicalAnd(left, right ast.Expression, env *object.Environment) object.Object {
+	l := e.Eval(left, env)
+	if isError(l) {
+		return l
+	}
+	if !isTruthy(l) {
+		return object.FALSE
+	}
+
+	r := e.Eval(right, env)
+	if isError(r) {
+		return r
+	}
+	return nativeBoolToBooleanObject(isTruthy(r))
+}
+
+// evalLogicalOr evaluates right only if left is falsy.
+func (e *Evaluator) evalLogicalOr(left, right ast.Expression, env *object.Environment) object.Object {
+	l := e.Eval(left, env)
+	if isError(l) {
+		return l
+	}
+	if isTruthy(l) {
+		return object.TRUE
+	}
+
+	r := e.Eval(right, env)
+	if isError(r) {
+		return r
+	}
+	return nativeBoolToBooleanObject(isTruthy(r))
+}
+
+// evalBooleanInfixExpression compares objects by identity, which is equality
+// for the true, false and null singletons.
+func evalBooleanInfixExpression(operator string, left, right object.Object) object.Object {
+	if operator == "==" {
+		return nativeBoolToBooleanObject(left == right)
+	}
+	return nativeBoolToBooleanObject(left != right)
+}
+
+func evalIntegerInfixExpression(operator string, left, right *object.Integer) object.Object {
+	switch operator {
+	case "+":
+		return &object.Integer{Value: left.Value + right.Value}
+	case "-":
+		return &object.Integer{Value: left.Value - right.Value}
+	case "*":
+		return &object.Integer{Value: left.Value * right.Value}
+	case "/":
+		if right.Value == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: left.Value / right.Value}
+	case "%":
+		if right.Value == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: left.Value % right.Value}
+	case "**":
+		if right.Value < 0 {
+			return newError("negative exponent: %d", right.Value)
+		}
+		return &object.Integer{Value: intPow(left.Value, right.Value)}
+	case "<":
+		return nativeBoolToBooleanObject(left.Value < right.Value)
+	case ">":
+		return nativeBoolToBooleanObject(left.Value > right.Value)
+	case "<=":
+		return nativeBoolToBooleanObject(left.Value <= right.Value)
+	case ">=":
+		return nativeBoolToBooleanObject(left.Value >= right.Value)
+	case "==":
+		return nativeBoolToBooleanObject(left.Value == right.Value)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Value != right.Value)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// intPow raises base to the power of exp, which is not negative, by repeated
+// squaring. Like the other integer operators, it wraps around on overflow.
+func intPow(base, exp int64) int64 {
+	result := int64(1)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+	}
+	return result
+}
+
+func evalFloatInfixExpression(operator string, left, right *object.Float) object.Object {
+	switch operator {
+	case "+":
+		return object.NewFloat(left.Value + right.Value)
+	case "-":
+		return object.NewFloat(left.Value - right.Value)
+	case "*":
+		return object.NewFloat(left.Value * right.Value)
+	case "/":
+		if right.Value == 0 {
+			return newError("division by zero")
+		}
+		return object.NewFloat(left.Value / right.Value)
+	case "**":
+		return object.NewFloat(math.Pow(left.Value, right.Value))
+	case "<":
+		return nativeBoolToBooleanObject(left.Value < right.Value)
+	case ">":
+		return nativeBoolToBooleanObject(left.Value > right.Value)
+	case "<=":
+		return nativeBoolToBooleanObject(left.Value <= right.Value)
+	case ">=":
+		return nativeBoolToBooleanObject(left.Value >= right.Value)
+	case "==":
+		return nativeBoolToBooleanObject(left.Value == right.Value)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Value != right.Value)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// toFloat promotes a number to a float, so that an integer can be mixed with
+// a float in arithmetic.
+func toFloat(obj object.Object) *object.Float {
+	if integer, ok := obj.(*object.Integer); ok {
+		return object.NewFloat(float64(integer.Value))
+	}
+	return obj.(*object.Float)
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func evalStringInfixExpression(operator string, left, right *object.String) object.Object {
+	switch operator {
+	case "+":
+		return &object.String{Value: left.Value + right.Value}
+	case "==":
+		return nativeBoolToBooleanObject(left.Value == right.Value)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Value != right.Value)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left.(*object.String), index.(*object.Integer))
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := e.Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := e.Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+// evalHashIndexExpression returns the value stored under index, or null when
+// there is none.
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hash.(*object.Hash).Pairs[key.HashKey()]
+	if !ok {
+		return object.NULL
+	}
+	return pair.Value
+}
+
+// evalArrayIndexExpression returns the element at index, or null when index is
+// out of range.
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	i := index.(*object.Integer).Value
+
+	if i < 0 || i >= int64(len(elements)) {
+		return object.NULL
+	}
+	return elements[i]
+}
+
+// evalStringIndexExpression returns the character at index as a string, or
+// null when index is out of range.
+func evalStringIndexExpression(str *object.String, index *object.Integer) object.Object {
+	chars := []rune(str.Value)
+	if index.Value < 0 || index.Value >= int64(len(chars)) {
+		return object.NULL
+	}
+	return &object.String{Value: string(chars[index.Value])}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+// isTruthy reports whether obj counts as true in a condition: everything but
+// false and null does.
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case object.FALSE, object.NULL:
+		return false
+	default:
+		return true
+	}
+}
+
+func isError(obj object.Object) bool {
+	_, ok := obj.(*object.Error)
+	return ok
+}
+
+func newError(format string, args ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, args...)}
+}
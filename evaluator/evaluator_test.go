@@ -0,0 +1,856 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/test"
+	"testing"
+)
+
+// errorMessage tells an expected error apart from an expected string in tests
+// whose expectations can be either.
+type errorMessage string
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+	result, _ := testEvalOutput(t, input)
+	return result
+}
+
+// testEvalOutput evaluates input and returns what it wrote to its output.
+func testEvalOutput(t *testing.T, input string) (object.Object, string) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		t.Fatalf("parser has %d errors: %q", len(errors), p.ParseErrors())
+	}
+
+	var out bytes.Buffer
+	result := New(WithOutput(&out)).Eval(program, object.NewEnvironment())
+	return result, out.String()
+}
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"5 + 5", 10},
+		{"5 * 5 + 10", 35},
+		{"(5 + 3) * 2", 16},
+		{"-(5 + 5)", -10},
+		{"2 * 2 * 2 - 4 / 2", 6},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestEvalIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"5 + 5 * 2", 15},
+		{"(5 + 5) * 2", 20},
+		{"10 / 2", 5},
+		{"7 / 2", 3},
+		{"7 % 4", 3},
+		{"5 - 10", -5},
+		{"-50 + 100 + -50", 0},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"2 <= 2", true},
+		{"1 >= 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"10 / 0", "division by zero"},
+		{"10 % 0", "division by zero"},
+		{"2 ** 3", 8},
+		{"2 ** 3 ** 2", 512},
+		{"(2 ** 3) ** 2", 64},
+		{"-2 ** 3", -8},
+		{"5 ** 0", 1},
+		{"2 * 3 ** 2", 18},
+		{"2 ** -1", "negative exponent: -1"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case bool:
+			assertBooleanObject(t, got, want)
+		case string:
+			assertErrorObject(t, got, want)
+		}
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"1.5 + 2.5", 4.0},
+		{"10.0 / 4", 2.5},
+		{"5 + 2.5", 7.5},
+		{"5.0 / 2", 2.5},
+		{"5 / 2", 2},
+		{"2.5 * 2 - 1", 4.0},
+		{"3.0 == 3.0", true},
+		{"3.0 != 3.1", true},
+		{"3.0 < 3.1", true},
+		{"3 > 3.1", false},
+		{"2.0 ** 3", 8.0},
+		{"4 ** 0.5", 2.0},
+		{"2 ** -1.0", 0.5},
+		{"1.0 / 0", "division by zero"},
+		{"1.5 % 2.5", "unknown operator: FLOAT % FLOAT"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case float64:
+			assertFloatObject(t, got, want)
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case bool:
+			assertBooleanObject(t, got, want)
+		case string:
+			assertErrorObject(t, got, want)
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"true", true},
+		{"false", false},
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!null", true},
+		{"true == true", true},
+		{"false != true", true},
+		{"true == false", false},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+		{"null == null", true},
+		{"null != false", true},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"true > 1", "type mismatch: BOOLEAN > INTEGER"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case bool:
+			assertBooleanObject(t, got, want)
+		case string:
+			assertErrorObject(t, got, want)
+		}
+	}
+}
+
+func TestEvalTernaryExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"true ? 1 : 2", 1},
+		{"false ? 1 : 2", 2},
+		{"null ? 1 : 2", 2},
+		{"0 ? 1 : 2", 1},
+		{"1 > 2 ? 1 : 2 > 1 ? 3 : 4", 3},
+		{"let x = 5; x % 2 == 0 ? \"even\" : \"odd\"", "odd"},
+		// only the branch taken is evaluated
+		{"true ? 1 : y", 1},
+		{"x ? 1 : 2", errorMessage("identifier not found: x")},
+		// a call in a branch is a tail call
+		{"let down = fn(n) { n == 0 ? 0 : down(n - 1) }; down(5000)", 0},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			assertStringObject(t, got, want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestEvalInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`let name = "you"; f"hello, ${name}!"`, "hello, you!"},
+		{`f"${1 + 2} is ${true}"`, "3 is true"},
+		{`f"${[1, 2]} and ${null}"`, "[1, 2] and null"},
+		{`f"${"a" + "b"}"`, "ab"},
+		{`f"${missing}"`, errorMessage("identifier not found: missing")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case string:
+			assertStringObject(t, got, want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestEvalIfElseExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (null) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (0) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (false) { 1 } else if (true) { 2 } else { 3 }", 2},
+		{"if (true) {}", nil},
+		{"if (true) { -true; 10 }", "unknown operator: -BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			assertErrorObject(t, got, want)
+		default:
+			assertNullObject(t, got)
+		}
+	}
+}
+
+func TestEvalLetStatements(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+		{"let a = 5; let a = a + 1; a;", 6},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+
+	assertNullObject(t, testEval(t, "let a = 5;"))
+}
+
+func TestEvalIdentifierError(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"foobar", "identifier not found: foobar"},
+		{"let a = b;", "identifier not found: b"},
+		{"if (true) { x }", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		assertErrorObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestEvalFunctionObject(t *testing.T) {
+	got := testEval(t, "fn(x) { x + 2; };")
+
+	fn, ok := got.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function, got=%T (%+v)", got, got)
+	}
+	test.AssertEqual(t, len(fn.Parameters), 1)
+	test.AssertEqual(t, fn.Parameters[0].String(), "x")
+	test.AssertEqual(t, fn.Body.String(), "{\n    (x + 2)\n}")
+}
+
+func TestEvalFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{"let x = 10; let addX = fn(y) { x + y }; addX(5);", 15},
+		{"let adder = fn(x) { fn(y) { x + y } }; let addTwo = adder(2); addTwo(3);", 5},
+		{"let x = 1; let shadow = fn(x) { x }; shadow(2) + x;", 3},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestEvalReturnStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+		{"let f = fn(x) { if (x > 0) { return x; } return -1; 100; }; f(5);", 5},
+		{"let f = fn(x) { if (x > 0) { return x; } return -1; 100; }; f(0);", -1},
+		// a return inside a call only ends the call
+		{"let f = fn() { return 1; }; f(); 2;", 2},
+		{"let f = fn() { return 1; }; f() + f();", 2},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestEvalErrorHandling(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"foobar", "identifier not found: foobar"},
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"10 / 0; 5", "division by zero"},
+		{"5 * (10 / 0)", "division by zero"},
+		{"-(10 / 0)", "division by zero"},
+		{"let f = fn(x) { x }; f(1, 2);", "wrong number of arguments: want=1, got=2"},
+		{"let f = fn(x, y) { x }; f(1);", "wrong number of arguments: want=2, got=1"},
+		{"5(1)", "not a function: INTEGER"},
+		{"let f = fn(x) { x }; f(10 / 0);", "division by zero"},
+		{"if (10 > 1) { if (10 > 1) { return true + false; } return 1; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		// an error in a condition is not mistaken for a truthy value
+		{"if (10 / 0) { 1 } else { 2 }", "division by zero"},
+		{"let f = fn() { let x = y; 1 }; f(); 2", "identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		assertErrorObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestEvalStringExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`"Hello World!"`, "Hello World!"},
+		{`"Hello" + " " + "World"`, "Hello World"},
+		{`let s = "foo"; s + s`, "foofoo"},
+		{`"foo" == "foo"`, true},
+		{`"foo" == "bar"`, false},
+		{`"foo" != "bar"`, true},
+		{`"hello"[0]`, "h"},
+		{`"hello"[4]`, "o"},
+		{`"hello"[5]`, nil},
+		{`"hello"[-1]`, nil},
+		{`"a" + 5`, errorMessage("type mismatch: STRING + INTEGER")},
+		{`"a" - "b"`, errorMessage("unknown operator: STRING - STRING")},
+		{`"a"["b"]`, errorMessage("index operator not supported: STRING[STRING]")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case string:
+			assertStringObject(t, got, want)
+		case bool:
+			assertBooleanObject(t, got, want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		default:
+			assertNullObject(t, got)
+		}
+	}
+}
+
+func TestEvalArrayLiterals(t *testing.T) {
+	got := testEval(t, "[1, 2 * 2, 3 + 3]")
+
+	result, ok := got.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array, got=%T (%+v)", got, got)
+	}
+	test.AssertEqual(t, len(result.Elements), 3)
+	assertIntegerObject(t, result.Elements[0], 1)
+	assertIntegerObject(t, result.Elements[1], 4)
+	assertIntegerObject(t, result.Elements[2], 6)
+
+	test.AssertEqual(t, testEval(t, `[]`).Inspect(), "[]")
+	test.AssertEqual(t, testEval(t, `[1, "a", [true]]`).Inspect(), "[1, a, [true]]")
+	assertErrorObject(t, testEval(t, "[1, x, 3]"), "identifier not found: x")
+}
+
+func TestEvalArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"[[1, 2], [3, 4]][0][1]", 2},
+		{"[[1, 2], [3, 4]][1][0]", 3},
+		{"[1, 2, 3][3]", nil},
+		{"[1, 2, 3][-1]", nil},
+		{"[][0]", nil},
+		{"[1][true]", "index operator not supported: ARRAY[BOOLEAN]"},
+		{"5[0]", "index operator not supported: INTEGER[INTEGER]"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			assertErrorObject(t, got, want)
+		default:
+			assertNullObject(t, got)
+		}
+	}
+}
+
+func TestEvalHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	got := testEval(t, input)
+	result, ok := got.(*object.Hash)
+	if !ok {
+		t.Fatalf("object is not Hash, got=%T (%+v)", got, got)
+	}
+
+	want := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		object.TRUE.HashKey():                      5,
+		object.FALSE.HashKey():                     6,
+	}
+	test.AssertEqual(t, len(result.Pairs), len(want))
+	for key, value := range want {
+		pair, ok := result.Pairs[key]
+		if !ok {
+			t.Fatalf("no pair for given key in Pairs")
+		}
+		assertIntegerObject(t, pair.Value, value)
+	}
+
+	test.AssertEqual(t, testEval(t, `{"b": 2, "a": 1}`).Inspect(), "{b: 2, a: 1}")
+	test.AssertEqual(t, testEval(t, `let h = {"b": 2, "a": 1}; h["c"] = 3; h["b"] = 4; h`).Inspect(), "{b: 4, a: 1, c: 3}")
+	assertErrorObject(t, testEval(t, `{[1]: 2}`), "unusable as hash key: ARRAY")
+	assertErrorObject(t, testEval(t, `{"a": x}`), "identifier not found: x")
+}
+
+func TestEvalHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`{1: 5}[true]`, nil},
+		{`{1.5: 5}[1.5]`, 5},
+		{`{1.0: 5}[1]`, nil},
+		{`{"foo": 5}[fn(x) { x }]`, "unusable as hash key: FUNCTION"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case string:
+			assertErrorObject(t, got, want)
+		default:
+			assertNullObject(t, got)
+		}
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	countdown := "let countdown = fn(n) { if (n == 0) { 0 } else { 1 + countdown(n - 1) } };"
+
+	eval := func(input string, opts ...Option) object.Object {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		return New(opts...).Eval(program, object.NewEnvironment())
+	}
+
+	// countdown(n) nests n+1 calls
+	assertIntegerObject(t, eval(countdown+"countdown(9)", WithMaxDepth(10)), 9)
+	assertErrorObject(t, eval(countdown+"countdown(10)", WithMaxDepth(10)), "maximum call depth 10 exceeded")
+
+	assertIntegerObject(t, eval(countdown+fmt.Sprintf("countdown(%d)", DefaultMaxDepth-1)), DefaultMaxDepth-1)
+	assertErrorObject(t, eval(countdown+fmt.Sprintf("countdown(%d)", DefaultMaxDepth)), "maximum call depth 1000 exceeded")
+
+	// the depth is back to zero after an error
+	e := New(WithMaxDepth(10))
+	env := object.NewEnvironment()
+	e.Eval(parser.New(lexer.New(countdown+"countdown(100)")).ParseProgram(), env)
+	assertIntegerObject(t, e.Eval(parser.New(lexer.New("countdown(9)")).ParseProgram(), env), 9)
+}
+
+func TestTailCallOptimization(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"let loop = fn(n) { if (n == 0) { 0 } else { loop(n - 1) } }; loop(1000000)", 0},
+		{"let loop = fn(n) { if (n == 0) { return 42; } return loop(n - 1); }; loop(1000000)", 42},
+		{"let sum = fn(n, acc) { if (n == 0) { acc } else { sum(n - 1, acc + n) } }; sum(100000, 0)", 5000050000},
+		// mutual recursion is made of tail calls too
+		{`let even = fn(n) { if (n == 0) { 1 } else { odd(n - 1) } };
+		  let odd = fn(n) { if (n == 0) { 0 } else { even(n - 1) } };
+		  even(100001)`, 0},
+		// a tail call to a builtin
+		{"let size = fn(arr) { len(arr) }; size([1, 2, 3])", 3},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+
+	// calls that are not in tail position still count towards the depth
+	assertErrorObject(t, testEval(t, "let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100000)"),
+		"maximum call depth 1000 exceeded")
+}
+
+func TestShortCircuitAnd(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   bool
+		output string
+	}{
+		{"false && side()", false, ""},
+		{"null && side()", false, ""},
+		{"true && side()", true, "called\n"},
+		{"1 && side() && false", false, "called\n"},
+	}
+
+	for _, tt := range tests {
+		input := `let side = fn() { puts("called"); true }; ` + tt.input
+		got, out := testEvalOutput(t, input)
+		assertBooleanObject(t, got, tt.want)
+		test.AssertEqual(t, out, tt.output)
+	}
+}
+
+func TestShortCircuitOr(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   bool
+		output string
+	}{
+		{"true || side()", true, ""},
+		{"1 || side()", true, ""},
+		{"false || side()", false, "called\n"},
+		{"null || side() || true", true, "called\n"},
+	}
+
+	for _, tt := range tests {
+		input := `let side = fn() { puts("called"); false }; ` + tt.input
+		got, out := testEvalOutput(t, input)
+		assertBooleanObject(t, got, tt.want)
+		test.AssertEqual(t, out, tt.output)
+	}
+
+	assertErrorObject(t, testEval(t, "false || x"), "identifier not found: x")
+}
+
+func TestEvalWhileLoop(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   interface{}
+		output string
+	}{
+		// blocks share the scope they are in, so let rebinds the counter
+		{"let i = 3; while (i > 0) { puts(i); let i = i - 1; }; i", 0, "3\n2\n1\n"},
+		{"while (false) { puts(1); }", nil, ""},
+		{"let f = fn() { let i = 0; while (true) { let i = i + 1; if (i == 5) { return i; } } }; f()", 5, ""},
+		{"while (x) { puts(1); }", errorMessage("identifier not found: x"), ""},
+		{"while (true) { puts(1); x; }", errorMessage("identifier not found: x"), "1\n"},
+	}
+
+	for _, tt := range tests {
+		got, out := testEvalOutput(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		case nil:
+			assertNullObject(t, got)
+		}
+		test.AssertEqual(t, out, tt.output)
+	}
+}
+
+func TestBreakInWhile(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   interface{}
+		output string
+	}{
+		{"let i = 0; while (true) { let i = i + 1; if (i > 3) { break; } puts(i); }; i", 4, "1\n2\n3\n"},
+		{"let i = 0; while (i < 10) { break; puts(i); }; i", 0, ""},
+		// break ends the innermost loop only
+		{`let i = 0; let total = 0;
+		  while (i < 3) {
+		    let i = i + 1;
+		    let j = 0;
+		    while (true) { let j = j + 1; let total = total + 1; if (j == 2) { break; } }
+		  };
+		  total`, 6, ""},
+		{"for (let i = 0; i < 10; let i = i + 1) { if (i == 2) { break; } puts(i); }", nil, "0\n1\n"},
+		{"break;", errorMessage("break outside of a loop"), ""},
+		{"let f = fn() { break; }; while (true) { f(); }", errorMessage("break outside of a loop"), ""},
+	}
+
+	for _, tt := range tests {
+		got, out := testEvalOutput(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		case nil:
+			assertNullObject(t, got)
+		}
+		test.AssertEqual(t, out, tt.output)
+	}
+}
+
+func TestContinueInWhile(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   interface{}
+		output string
+	}{
+		{"let i = 0; while (i < 5) { let i = i + 1; if (i % 2 == 0) { continue; } puts(i); }; i", 5, "1\n3\n5\n"},
+		{"let i = 0; let n = 0; while (i < 4) { let i = i + 1; let n = n + 1; continue; puts(i); }; n", 4, ""},
+		// continue in a for loop still runs the post statement
+		{"for (let i = 0; i < 5; let i = i + 1) { if (i < 3) { continue; } puts(i); }", nil, "3\n4\n"},
+		{"continue;", errorMessage("continue outside of a loop"), ""},
+	}
+
+	for _, tt := range tests {
+		got, out := testEvalOutput(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		case nil:
+			assertNullObject(t, got)
+		}
+		test.AssertEqual(t, out, tt.output)
+	}
+}
+
+func TestEvalAssignment(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"let x = 5; x = 10; x", 10},
+		{"let x = 5; let y = x; x = 20; y", 5},
+		{"let x = 5; x = x * 2", 10},
+		{"z = 5", errorMessage("assignment to undefined variable: z")},
+		{"let x = 5; x = y", errorMessage("identifier not found: y")},
+		// closures update the binding they captured
+		{"let n = 0; let inc = fn() { n = n + 1 }; inc(); inc(); n", 2},
+		{"let counter = fn() { let c = 0; fn() { c = c + 1 } }; let next = counter(); next(); next()", 2},
+		{"let i = 0; while (i < 5) { i = i + 1 }; i", 5},
+		{"let x = 1; ++x", 2},
+		{"let x = 1; x--; x", 0},
+		{"let a = [1, 2]; a[0] = 5; a[0]", 5},
+		{"let a = [1, 2]; let b = a; a[1] = 7; b[1]", 7},
+		{"let a = [1, 2]; a[0] = 5", 5},
+		{"let a = [1, 2]; ++a[0]; a[1]--; a[0] * 10 + a[1]", 21},
+		{`let h = {"n": 1}; h["n"]++`, 2},
+		{`let h = {"a": 1}; h["a"] = 2; h["a"]`, 2},
+		{`let h = {}; h[true] = 3; h[true]`, 3},
+		{"let a = [1, 2]; a[2] = 5", errorMessage("index out of range: 2")},
+		{"let a = [1, 2]; a[-1] = 5", errorMessage("index out of range: -1")},
+		{`let a = [1, 2]; a["x"] = 5`, errorMessage("index assignment not supported: ARRAY[STRING]")},
+		{"let h = {}; h[[1]] = 5", errorMessage("unusable as hash key: ARRAY")},
+		{`let s = "ab"; s[0] = "c"`, errorMessage("index assignment not supported: STRING[INTEGER]")},
+		{"let a = [1]; a[0] = y", errorMessage("identifier not found: y")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestEvalCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input string
+		want  interface{}
+	}{
+		{"let x = 5; x += 3; x", 8},
+		{"let x = 10; x -= 4; x", 6},
+		{"let x = 3; x *= 4; x", 12},
+		{"let x = 9; x /= 2; x", 4},
+		{"let x = 1.5; x += 1; x", 2.5},
+		{`let s = "hello"; s += " world"; s`, "hello world"},
+		{"let sum = 0; for (let i = 1; i <= 4; i += 1) { sum += i }; sum", 10},
+		{"x += 1", errorMessage("assignment to undefined variable: x")},
+		{`let x = 1; x += "a"`, errorMessage("type mismatch: INTEGER + STRING")},
+		{"let x = 1; x /= 0", errorMessage("division by zero")},
+	}
+
+	for _, tt := range tests {
+		got := testEval(t, tt.input)
+		switch want := tt.want.(type) {
+		case int:
+			assertIntegerObject(t, got, int64(want))
+		case float64:
+			assertFloatObject(t, got, want)
+		case string:
+			assertStringObject(t, got, want)
+		case errorMessage:
+			assertErrorObject(t, got, string(want))
+		}
+	}
+}
+
+func TestPipeOperatorEval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"5 |> fn(x) { x * 2 }", 10},
+		{"[1, 2, 3] |> len", 3},
+		{"let double = fn(x) { x * 2 }; 1 + 2 |> double |> double", 12},
+	}
+
+	for _, tt := range tests {
+		assertIntegerObject(t, testEval(t, tt.input), tt.want)
+	}
+}
+
+func TestImmutableClosures(t *testing.T) {
+	tests := []struct {
+		input   string
+		mutable int64
+		want    int64
+	}{
+		// a later rebinding is not seen by the closure
+		{"let x = 1; let f = fn() { x }; x = 2; f()", 2, 1},
+		{"let x = 1; let f = fn() { x }; let x = 3; f()", 3, 1},
+		// nor is a rebinding by the closure seen outside of it
+		{"let x = 1; let f = fn() { x = 5 }; f(); x", 5, 1},
+		// but the closure keeps its own state, and can call itself
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c()", 2, 2},
+		{"let fact = fn(n) { n < 2 ? 1 : n * fact(n - 1) }; fact(5)", 120, 120},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if errors := p.Errors(); len(errors) != 0 {
+			t.Fatalf("parser has %d errors: %q", len(errors), p.ParseErrors())
+		}
+
+		assertIntegerObject(t, New().Eval(program, object.NewEnvironment()), tt.mutable)
+		assertIntegerObject(t, New(WithImmutableClosures()).Eval(program, object.NewEnvironment()), tt.want)
+	}
+}
+
+func TestUnusedVarWarnings(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"let x = 5; 3", []string{"x declared but not used"}},
+		{"let x = 5; x", nil},
+		{"let x = 1; let y = 2; let z = x; 3", []string{"y declared but not used", "z declared but not used"}},
+		// reads by functions count, and parameters are not warned about
+		{"let x = 1; let f = fn(unused) { x }; f(2)", nil},
+		{"let n = 0; let f = fn() { let local = 1; 2 }; f(); n", nil},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if errors := p.Errors(); len(errors) != 0 {
+			t.Fatalf("parser has %d errors: %q", len(errors), p.ParseErrors())
+		}
+
+		e := New(WithUnusedVarWarnings())
+		e.Eval(program, object.NewEnvironment())
+		test.AssertEqual(t, fmt.Sprint(e.Warnings()), fmt.Sprint(tt.want))
+	}
+
+	// without the option, there are no warnings
+	e := New()
+	e.Eval(parser.New(lexer.New("let x = 5; 3")).ParseProgram(), object.NewEnvironment())
+	test.AssertEqual(t, len(e.Warnings()), 0)
+}
@@ -0,0 +1,117 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleLoader finds and evaluates the module an import statement names.
+type ModuleLoader interface {
+	Load(path string) (*object.Module, error)
+}
+
+// importerLoader is implemented by the loaders that can evaluate a module
+// with the settings of the evaluator importing it.
+type importerLoader interface {
+	loadFor(path string, importer *Evaluator) (*object.Module, error)
+}
+
+// FileModuleLoader loads modules from .mk source files. A relative path is
+// looked up in each of SearchPaths in turn, and the extension may be left
+// out: import "math" loads math.mk.
+//
+// Every module is evaluated once, in an environment of its own, however many
+// times it is imported.
+type FileModuleLoader struct {
+	SearchPaths []string
+
+	modules map[string]*object.Module // by file, once loaded
+	loading map[string]bool           // files whose evaluation is in progress
+}
+
+// Load loads the module at path, evaluated with the default options. Modules
+// imported by a program are evaluated with the options of its evaluator
+// instead.
+func (l *FileModuleLoader) Load(path string) (*object.Module, error) {
+	return l.loadFor(path, New())
+}
+
+func (l *FileModuleLoader) loadFor(path string, importer *Evaluator) (*object.Module, error) {
+	file, err := l.find(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := l.modules[file]; ok {
+		return module, nil
+	}
+	if l.loading[file] {
+		return nil, fmt.Errorf("import cycle through %s", file)
+	}
+
+	if l.loading == nil {
+		l.loading = make(map[string]bool)
+	}
+	l.loading[file] = true
+	defer delete(l.loading, file)
+
+	module, err := l.evalFile(file, importer)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.modules == nil {
+		l.modules = make(map[string]*object.Module)
+	}
+	l.modules[file] = module
+	return module, nil
+}
+
+func (l *FileModuleLoader) find(path string) (string, error) {
+	if filepath.Ext(path) == "" {
+		path += ".mk"
+	}
+
+	if filepath.IsAbs(path) {
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	for _, dir := range l.SearchPaths {
+		file := filepath.Join(dir, path)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("module %s not found in %s", path, strings.Join(l.SearchPaths, ", "))
+}
+
+// evalFile evaluates file with the options of importer.
+func (l *FileModuleLoader) evalFile(file string, importer *Evaluator) (*object.Module, error) {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if errs := p.ParseErrors(); len(errs) != 0 {
+		return nil, fmt.Errorf("%s: %w", file, errors.Join(errs...))
+	}
+
+	env := object.NewEnvironment()
+	if result := importer.moduleEvaluator(l).Eval(program, env); isError(result) {
+		return nil, fmt.Errorf("%s: %s", file, result.(*object.Error).Message)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return &object.Module{Name: name, Env: env}, nil
+}
@@ -0,0 +1,125 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"strings"
+	"testing"
+)
+
+func testEvalWithLoader(t *testing.T, input string, loader ModuleLoader, opts ...Option) object.Object {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		t.Fatalf("parser has %d errors: %q", len(errors), p.ParseErrors())
+	}
+
+	opts = append(opts, WithModuleLoader(loader))
+	return New(opts...).Eval(program, object.NewEnvironment())
+}
+
+func TestModuleImport(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{`import "square"; square(4)`, 16},
+		{`import "square.mk"; square(5)`, 25},
+		{`import "geometry"; area(2)`, 12},
+		// names a module imports are bound by importing it too
+		{`import "geometry"; square(3)`, 9},
+		// a module's bindings can be shadowed after importing it
+		{`import "geometry"; let pi = 4; area(1)`, 3},
+	}
+
+	for _, tt := range tests {
+		loader := &FileModuleLoader{SearchPaths: []string{"nowhere", "testdata"}}
+		assertIntegerObject(t, testEvalWithLoader(t, tt.input, loader), tt.want)
+	}
+}
+
+func TestModuleImportErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`import "missing"`, `cannot import "missing": module missing.mk not found in testdata`},
+		{`import "cycle"`, `cannot import "cycle": testdata/cycle.mk: cannot import "cycle": import cycle through testdata/cycle.mk`},
+		{`import "broken"`, `cannot import "broken": testdata/broken.mk: 1:9: no prefix parse function for ;`},
+		{`let f = fn() { import "square"; square(3) }; f()`, `cannot import "square": import is only allowed at the top level`},
+		{`for (let i = 0; i < 1; i += 1) { import "square" }`, `cannot import "square": import is only allowed at the top level`},
+	}
+
+	for _, tt := range tests {
+		loader := &FileModuleLoader{SearchPaths: []string{"testdata"}}
+		got := testEvalWithLoader(t, tt.input, loader)
+		errObj, ok := got.(*object.Error)
+		if !ok {
+			t.Fatalf("object is not Error, got=%T (%+v)", got, got)
+		}
+		if !strings.HasPrefix(errObj.Message, tt.want) {
+			t.Errorf("wrong error message, got=%q, want prefix %q", errObj.Message, tt.want)
+		}
+	}
+
+	assertErrorObject(t, testEval(t, `import "square"`), `cannot import "square": no module loader`)
+}
+
+func TestModuleImporterSettings(t *testing.T) {
+	// a module writes where the importing program does
+	var out bytes.Buffer
+	loader := &FileModuleLoader{SearchPaths: []string{"testdata"}}
+	got := testEvalWithLoader(t, `import "greeting"; greet("you")`, loader, WithOutput(&out))
+	assertStringObject(t, got, "hello, you")
+	if out.String() != "loading greeting\n" {
+		t.Errorf("wrong output, got=%q", out.String())
+	}
+
+	// and has its calls nest no deeper
+	loader = &FileModuleLoader{SearchPaths: []string{"testdata"}}
+	got = testEvalWithLoader(t, `import "countdown"`, loader, WithMaxDepth(5))
+	assertErrorObject(t, got, `cannot import "countdown": testdata/countdown.mk: maximum call depth 5 exceeded`)
+}
+
+// staticLoader serves modules made up by the test, by path.
+type staticLoader map[string]*object.Module
+
+func (l staticLoader) Load(path string) (*object.Module, error) {
+	if module, ok := l[path]; ok {
+		return module, nil
+	}
+	return nil, fmt.Errorf("no module %s", path)
+}
+
+func TestCustomModuleLoader(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("answer", &object.Integer{Value: 42})
+	loader := staticLoader{"answers": {Name: "answers", Env: env}}
+
+	assertIntegerObject(t, testEvalWithLoader(t, `import "answers"; answer`, loader), 42)
+	assertErrorObject(t, testEvalWithLoader(t, `import "questions"`, loader), `cannot import "questions": no module questions`)
+}
+
+func TestModuleLoadedOnce(t *testing.T) {
+	loader := &FileModuleLoader{SearchPaths: []string{"testdata"}}
+
+	first, err := loader.Load("square")
+	if err != nil {
+		t.Fatalf("Load failed: %s", err)
+	}
+	second, err := loader.Load("square.mk")
+	if err != nil {
+		t.Fatalf("Load failed: %s", err)
+	}
+
+	if first != second {
+		t.Errorf("module was loaded twice")
+	}
+	if first.Name != "square" {
+		t.Errorf("module has wrong name, got=%q", first.Name)
+	}
+}
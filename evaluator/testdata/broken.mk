@@ -0,0 +1 @@
+let x = ;
@@ -0,0 +1,2 @@
+let count = fn(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } };
+let ten = count(10);
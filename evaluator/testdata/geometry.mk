@@ -0,0 +1,4 @@
+import "square";
+
+let pi = 3;
+let area = fn(r) { pi * square(r) };
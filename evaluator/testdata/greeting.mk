@@ -0,0 +1,3 @@
+puts("loading greeting");
+
+let greet = fn(name) { "hello, " + name };
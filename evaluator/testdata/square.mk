@@ -0,0 +1 @@
+let square = fn(x) { x * x };
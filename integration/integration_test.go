@@ -0,0 +1,106 @@
+// Package integration runs Monkey programs through the whole pipeline, from
+// lexing to evaluation, to catch what the unit tests of each stage miss.
+package integration
+
+import (
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"testing"
+)
+
+type programTest struct {
+	input    string
+	wantType object.ObjectType
+	want     string // the Inspect of the result
+}
+
+func run(t *testing.T, tests []programTest) {
+	t.Helper()
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if errs := p.ParseErrors(); len(errs) != 0 {
+			t.Errorf("%q: parser has %d errors: %q", tt.input, len(errs), errs)
+			continue
+		}
+
+		got := evaluator.New().Eval(program, object.NewEnvironment())
+		if got == nil {
+			t.Errorf("%q: got nil", tt.input)
+			continue
+		}
+		if got.Type() != tt.wantType || got.Inspect() != tt.want {
+			t.Errorf("%q: got %s %s, want %s %s", tt.input, got.Type(), got.Inspect(), tt.wantType, tt.want)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	run(t, []programTest{
+		{"1 + 2 * 3", object.INTEGER_OBJ, "7"},
+		{"(1 + 2) * 3", object.INTEGER_OBJ, "9"},
+		{"10 - 4 / 2 % 3", object.INTEGER_OBJ, "8"},
+		{"2 ** 3 ** 2", object.INTEGER_OBJ, "512"},
+		{"-5 + 10", object.INTEGER_OBJ, "5"},
+		{"1.5 * 2", object.FLOAT_OBJ, "3"},
+		{"7 / 2.0", object.FLOAT_OBJ, "3.5"},
+		{"1 < 2 == true", object.BOOLEAN_OBJ, "true"},
+		{"2 >= 3 != false", object.BOOLEAN_OBJ, "false"},
+		{"!true || !!1", object.BOOLEAN_OBJ, "true"},
+		{"false && undefined", object.BOOLEAN_OBJ, "false"},
+		{`"mon" + "key"`, object.STRING_OBJ, "monkey"},
+		{`"a" == "a"`, object.BOOLEAN_OBJ, "true"},
+		{"1 > 2 ? 1 : 2", object.INTEGER_OBJ, "2"},
+		{"3 |> fn(x) { x * x }", object.INTEGER_OBJ, "9"},
+		{"let x = 1; x += 4; x *= 2; x", object.INTEGER_OBJ, "10"},
+		{"let x = 1; ++x; x++; x", object.INTEGER_OBJ, "3"},
+		{"1 / 0", object.ERROR_OBJ, "ERROR: division by zero"},
+		{"5 + true", object.ERROR_OBJ, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	run(t, []programTest{
+		{"let f = fn(x) { x * x }; f(5)", object.INTEGER_OBJ, "25"},
+		{"let add = fn(a) { fn(b) { a + b } }; add(2)(3)", object.INTEGER_OBJ, "5"},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c(); c()", object.INTEGER_OBJ, "3"},
+		{"let twice = fn(f, x) { f(f(x)) }; twice(fn(x) { x + 3 }, 1)", object.INTEGER_OBJ, "7"},
+		{"let x = 1; let f = fn() { x }; let g = fn(x) { f() }; g(2)", object.INTEGER_OBJ, "1"},
+		{"fn(x) { return x; 99 }(4)", object.INTEGER_OBJ, "4"},
+	})
+}
+
+func TestRecursion(t *testing.T) {
+	run(t, []programTest{
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(10)", object.INTEGER_OBJ, "3628800"},
+		{"let fib = fn(n) { n < 2 ? n : fib(n - 1) + fib(n - 2) }; fib(15)", object.INTEGER_OBJ, "610"},
+		// tail calls do not grow the stack
+		{"let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + n) } }; loop(100000, 0)", object.INTEGER_OBJ, "5000050000"},
+		{"let even = fn(n) { n == 0 ? true : odd(n - 1) }; let odd = fn(n) { n == 0 ? false : even(n - 1) }; even(5001)", object.BOOLEAN_OBJ, "false"},
+	})
+}
+
+func TestArrays(t *testing.T) {
+	run(t, []programTest{
+		{"[1, 2 * 2, 3 + 3]", object.ARRAY_OBJ, "[1, 4, 6]"},
+		{"let a = [1, 2, 3]; a[0] + a[2]", object.INTEGER_OBJ, "4"},
+		{"[1, 2, 3][3]", object.NULL_OBJ, "null"},
+		{"let a = [1, 2]; a[1] = 5; a", object.ARRAY_OBJ, "[1, 5]"},
+		{"len([1, 2, 3]) + len(\"ab\")", object.INTEGER_OBJ, "5"},
+		{"let sum = 0; for (let i = 0; i < 4; i += 1) { sum += [1, 2, 3, 4][i] }; sum", object.INTEGER_OBJ, "10"},
+		{"let i = 0; while (true) { i += 1; if (i == 3) { break } }; i", object.INTEGER_OBJ, "3"},
+	})
+}
+
+func TestHashes(t *testing.T) {
+	run(t, []programTest{
+		{`{"a": 1, "b": 2}`, object.HASH_OBJ, `{a: 1, b: 2}`},
+		{`let h = {"one": 1, 2: "two", true: 3}; h["one"] + h[true]`, object.INTEGER_OBJ, "4"},
+		{`{"a": 1}["b"]`, object.NULL_OBJ, "null"},
+		{`let h = {}; h["k"] = "v"; h["k"]`, object.STRING_OBJ, "v"},
+		{`let key = "x"; {key: fn(n) { n * 2 }}["x"](21)`, object.INTEGER_OBJ, "42"},
+		{`{[1]: 2}`, object.ERROR_OBJ, "ERROR: unusable as hash key: ARRAY"},
+	})
+}
@@ -0,0 +1,22 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"monkey/token"
+)
+
+// DumpTokens writes the tokens of l to w, up to and including EOF, one per
+// line:
+//
+//	LET  "let"  1:1
+//	NAME  "x"  1:5
+func DumpTokens(l *Lexer, w io.Writer) {
+	for {
+		tok := l.NextToken()
+		fmt.Fprintf(w, "%s  %q  %s\n", tok.Type, tok.Literal, tok.Pos)
+		if tok.Type == token.EOF {
+			return
+		}
+	}
+}
@@ -1,96 +1,300 @@
 package lexer
 
-import "monkey/token"
+import (
+	"monkey/token"
+	"strings"
+)
+
+// escapes maps the char following a '\' in a string literal to the byte it
+// stands for.
+var escapes = map[byte]byte{
+	'n':  '\n',
+	't':  '\t',
+	'\\': '\\',
+	'"':  '"',
+}
 
 type Lexer struct {
 	input        string
 	currPosition int  // current position in input (points to curr char)
 	nextPosition int  // current reading position in input (after curr char)
 	ch           byte // curr char under examination
+	line         int  // line of curr char, starting at 1
+	col          int  // column of curr char, starting at 1
+
+	errors   []string
+	comments []token.Comment
+
+	cachedPeek token.Token // token lexed ahead by Peek
+	hasPeek    bool        // whether cachedPeek is waiting to be returned
+
+	// interpolated strings: the '{' nesting depth within each open ${ ... },
+	// whether the curr char starts a ${, and whether an f-string resumes
+	// after the } just read
+	interps     []int
+	interpStart bool
+	resumeStr   bool
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
+// Errors returns problems found in the input that do not map onto a token,
+// such as an unterminated block comment.
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+// Comments returns the comments skipped so far, in source order.
+func (l *Lexer) Comments() []token.Comment {
+	return l.comments
+}
+
+// addComment records the comment from pos up to the curr char, which has just
+// been skipped.
+func (l *Lexer) addComment(pos token.Position) {
+	lineStart := pos.Offset - (pos.Col - 1)
+	l.comments = append(l.comments, token.Comment{
+		Text:     l.input[pos.Offset:l.currPosition],
+		Pos:      pos,
+		Trailing: strings.TrimSpace(l.input[lineStart:pos.Offset]) != "",
+	})
+}
+
+// Position returns the position of the char currently under examination.
+func (l *Lexer) Position() token.Position {
+	return token.Position{Line: l.line, Col: l.col, Offset: l.currPosition}
+}
+
+// Peek returns the next token without consuming it: the following call to
+// NextToken returns the same token.
+func (l *Lexer) Peek() token.Token {
+	if !l.hasPeek {
+		l.cachedPeek = l.NextToken()
+		l.hasPeek = true
+	}
+	return l.cachedPeek
+}
+
 func (l *Lexer) NextToken() token.Token {
+	if l.hasPeek {
+		l.hasPeek = false
+		return l.cachedPeek
+	}
+
 	var tok token.Token
 
+	if l.resumeStr {
+		l.resumeStr = false
+		return l.readStringPart(l.Position())
+	}
+
 	l.skipWhitespace()
 
+	pos := l.Position()
+
 	switch l.ch {
 	case '+':
-		tok = token.New(token.PLUS, l.ch)
+		if l.peekChar() == '+' {
+			tok = l.readTwoCharToken(token.INCREMENT)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.PLUS_ASSIGN)
+		} else {
+			tok = token.New(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = token.New(token.MINUS, l.ch)
+		if l.peekChar() == '-' {
+			tok = l.readTwoCharToken(token.DECREMENT)
+		} else if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.ARROW)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.MINUS_ASSIGN)
+		} else {
+			tok = token.New(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = token.New(token.STAR, l.ch)
+		if l.peekChar() == '*' {
+			tok = l.readTwoCharToken(token.POWER)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.STAR_ASSIGN)
+		} else {
+			tok = token.New(token.STAR, l.ch)
+		}
 	case '/':
-		tok = token.New(token.SLASH, l.ch)
+		if l.peekChar() == '/' {
+			l.skipLineComment()
+			l.addComment(pos)
+			return l.NextToken()
+		}
+		if l.peekChar() == '*' {
+			l.skipBlockComment()
+			l.addComment(pos)
+			return l.NextToken()
+		}
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = token.New(token.SLASH, l.ch)
+		}
+	case '%':
+		tok = token.New(token.PERCENT, l.ch)
 	case '>':
-		tok = token.New(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.GTE)
+		} else {
+			tok = token.New(token.GT, l.ch)
+		}
 	case '<':
-		tok = token.New(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.LTE)
+		} else {
+			tok = token.New(token.LT, l.ch)
+		}
 	case '(':
 		tok = token.New(token.LPAREN, l.ch)
 	case ')':
 		tok = token.New(token.RPAREN, l.ch)
 	case '{':
+		if n := len(l.interps); n > 0 {
+			l.interps[n-1]++
+		}
 		tok = token.New(token.LBRACE, l.ch)
 	case '}':
-		tok = token.New(token.RBRACE, l.ch)
+		if n := len(l.interps); n > 0 && l.interps[n-1] == 0 {
+			l.interps = l.interps[:n-1]
+			l.resumeStr = true
+			tok = token.New(token.INTERP_END, l.ch)
+		} else {
+			if n > 0 {
+				l.interps[n-1]--
+			}
+			tok = token.New(token.RBRACE, l.ch)
+		}
+	case '$':
+		if l.interpStart {
+			l.interpStart = false
+			l.interps = append(l.interps, 0)
+			tok = l.readTwoCharToken(token.INTERP_START)
+		} else {
+			tok = token.New(token.ILLEGAL, l.ch)
+		}
+	case '[':
+		tok = token.New(token.LBRACKET, l.ch)
+	case ']':
+		tok = token.New(token.RBRACKET, l.ch)
 	case '=':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok.Literal = string(ch) + string(l.ch)
-			tok.Type = token.EQ
+			tok = l.readTwoCharToken(token.EQ)
 		} else {
 			tok = token.New(token.ASSIGN, l.ch)
 		}
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok.Literal = string(ch) + string(l.ch)
-			tok.Type = token.NEQ
+			tok = l.readTwoCharToken(token.NEQ)
 		} else {
 			tok = token.New(token.BANG, l.ch)
 		}
+	case '&':
+		if l.peekChar() == '&' {
+			tok = l.readTwoCharToken(token.AND)
+		} else {
+			tok = token.New(token.ILLEGAL, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			tok = l.readTwoCharToken(token.OR)
+		} else if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.PIPE)
+		} else {
+			tok = token.New(token.ILLEGAL, l.ch)
+		}
 	case ',':
 		tok = token.New(token.COMMA, l.ch)
 	case ';':
 		tok = token.New(token.SEMICOLON, l.ch)
+	case ':':
+		tok = token.New(token.COLON, l.ch)
+	case '?':
+		tok = token.New(token.QUESTION, l.ch)
+	case '"':
+		tok.Type, tok.Literal = l.readString()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
+		if l.ch == 'f' && l.peekChar() == '"' {
+			l.readChar() // f
+			l.readChar() // "
+			return l.readStringPart(pos)
+		}
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos = pos
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Type, tok.Literal = l.readNumber()
+			tok.Pos = pos
 			return tok
 		} else {
 			tok = token.New(token.ILLEGAL, l.ch)
 		}
 	}
 
+	tok.Pos = pos
 	l.readChar()
 	return tok
 }
 
+// readTwoCharToken consumes the curr and next char as a single token, e.g. ==
+func (l *Lexer) readTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		l.readChar()
 	}
 }
 
+// skipLineComment skips a '//' comment up to, but not including, the end of
+// the line.
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+}
+
+// skipBlockComment skips a '/* ... */' comment. Block comments do not nest:
+// the first '*/' closes the comment. Reaching EOF first records an error.
+func (l *Lexer) skipBlockComment() {
+	l.readChar() // /
+	l.readChar() // *
+
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.ch == 0 {
+			l.errors = append(l.errors, "unterminated block comment")
+			return
+		}
+		l.readChar()
+	}
+
+	l.readChar() // *
+	l.readChar() // /
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.col = 0
+	}
+	l.col += 1
+
 	if l.nextPosition >= len(l.input) {
 		l.ch = 0 // ASCII code for NUL (null)
 	} else {
@@ -112,8 +316,113 @@ func (l *Lexer) readIdentifier() string {
 	return l.readWhile(isLetter)
 }
 
-func (l *Lexer) readNumber() string {
-	return l.readWhile(isDigit)
+// radixDigits maps an integer literal's prefix char, as in 0x, 0o or 0b, to
+// the digits allowed after the prefix.
+var radixDigits = map[byte]func(byte) bool{
+	'x': isHexDigit,
+	'X': isHexDigit,
+	'o': isOctalDigit,
+	'O': isOctalDigit,
+	'b': isBinaryDigit,
+	'B': isBinaryDigit,
+}
+
+// readNumber reads an integer, or a float when the integer part is followed
+// by a '.'. The fractional part may be empty, so "3." is a float too. Integers
+// may also be written in hex (0x), octal (0o) or binary (0b).
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	position := l.currPosition
+
+	if isRadixDigit, ok := radixDigits[l.peekChar()]; ok && l.ch == '0' {
+		l.readChar() // 0
+		l.readChar() // x, o or b
+		l.readWhile(isRadixDigit)
+		return token.INT, l.input[position:l.currPosition]
+	}
+
+	l.readWhile(isDigit)
+
+	if l.ch != '.' {
+		return token.INT, l.input[position:l.currPosition]
+	}
+
+	l.readChar() // .
+	l.readWhile(isDigit)
+
+	return token.FLOAT, l.input[position:l.currPosition]
+}
+
+// readString reads a double-quoted string literal, processing escape
+// sequences, and leaves the closing quote as the current char. An unterminated
+// string is ILLEGAL, with the partial literal (opening quote included).
+func (l *Lexer) readString() (token.TokenType, string) {
+	var out strings.Builder
+
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '"':
+			return token.STRING, out.String()
+		case 0:
+			return token.ILLEGAL, `"` + out.String()
+		case '\\':
+			l.readChar()
+			if l.ch == 0 {
+				return token.ILLEGAL, `"` + out.String()
+			}
+			writeEscape(&out, l.ch)
+		default:
+			out.WriteByte(l.ch)
+		}
+	}
+}
+
+// readStringPart reads the text of an f-string from the curr char up to the
+// closing quote, which it consumes, or up to a ${, which is left to be lexed as
+// INTERP_START. A literal '$' before a '{' is written \$.
+func (l *Lexer) readStringPart(pos token.Position) token.Token {
+	var out strings.Builder
+
+	for {
+		switch l.ch {
+		case '"':
+			l.readChar()
+			return token.Token{Type: token.STRING_PART, Literal: out.String(), Pos: pos}
+		case 0:
+			return token.Token{Type: token.ILLEGAL, Literal: `f"` + out.String(), Pos: pos}
+		case '$':
+			if l.peekChar() == '{' {
+				l.interpStart = true
+				return token.Token{Type: token.STRING_PART, Literal: out.String(), Pos: pos}
+			}
+			out.WriteByte(l.ch)
+		case '\\':
+			l.readChar()
+			if l.ch == 0 {
+				return token.Token{Type: token.ILLEGAL, Literal: `f"` + out.String(), Pos: pos}
+			}
+			if l.ch == '$' {
+				out.WriteByte('$')
+			} else {
+				writeEscape(&out, l.ch)
+			}
+		default:
+			out.WriteByte(l.ch)
+		}
+		l.readChar()
+	}
+}
+
+// writeEscape writes the byte that \ch stands for.
+func writeEscape(out *strings.Builder, ch byte) {
+	if escaped, ok := escapes[ch]; ok {
+		out.WriteByte(escaped)
+	} else {
+		// unknown escapes are kept verbatim
+		out.WriteByte('\\')
+		out.WriteByte(ch)
+	}
 }
 
 func (l *Lexer) peekChar() byte {
@@ -131,3 +440,15 @@ func isLetter(ch byte) bool {
 func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func isOctalDigit(ch byte) bool {
+	return '0' <= ch && ch <= '7'
+}
+
+func isBinaryDigit(ch byte) bool {
+	return ch == '0' || ch == '1'
+}
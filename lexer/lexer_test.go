@@ -1,10 +1,33 @@
 package lexer
 
 import (
+	"bytes"
 	"monkey/token"
+	"strings"
 	"testing"
 )
 
+type expectedToken struct {
+	expectedType    token.TokenType
+	expectedLiteral string
+}
+
+// assertTokens lexes input and checks the resulting tokens one by one.
+func assertTokens(t *testing.T, input string, tests []expectedToken) {
+	t.Helper()
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong token type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
 func TestNextToken(t *testing.T) {
 	input := `
 		let five = 5;
@@ -16,13 +39,16 @@ func TestNextToken(t *testing.T) {
 
 		let res = add(five, ten);
 		
-		!-/*5;
+		!-*/5;
 		5 < 10 > 5;
 
 		if (5 < 10) { return true; } else { return false; }
 
 		10 == 10;
 		10 != 9;
+
+		3.14;
+		0.5;
 	`
 
 	tests := []struct {
@@ -65,10 +91,10 @@ func TestNextToken(t *testing.T) {
 		{token.NAME, "ten"},
 		{token.RPAREN, ")"},
 		{token.SEMICOLON, ";"},
-		{token.BANG, "!"}, // !-/*5;
+		{token.BANG, "!"}, // !-*/5; ("/*" would open a block comment)
 		{token.MINUS, "-"},
-		{token.SLASH, "/"},
 		{token.STAR, "*"},
+		{token.SLASH, "/"},
 		{token.INT, "5"},
 		{token.SEMICOLON, ";"},
 		{token.INT, "5"}, // 5 < 10 > 5;
@@ -102,6 +128,10 @@ func TestNextToken(t *testing.T) {
 		{token.NEQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.FLOAT, "3.14"}, // 3.14;
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "0.5"}, // 0.5;
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -117,3 +147,494 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStringLiteralLexing(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"hello world"`, token.STRING, "hello world"},
+		{`""`, token.STRING, ""},
+		{`"a\nb\tc\\d\"e"`, token.STRING, "a\nb\tc\\d\"e"},
+		{`"unknown \q"`, token.STRING, `unknown \q`},
+		{`"unterminated`, token.ILLEGAL, `"unterminated`},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong token type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after string, got=%q", i, tok.Type)
+		}
+	}
+}
+
+func TestFloatLiteralLexing(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"0.5", token.FLOAT, "0.5"},
+		{"3.14", token.FLOAT, "3.14"},
+		{"100.0", token.FLOAT, "100.0"},
+		{"3.", token.FLOAT, "3."},
+		{"42", token.INT, "42"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong token type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after number, got=%q", i, tok.Type)
+		}
+	}
+}
+
+func TestSingleLineCommentLexing(t *testing.T) {
+	commented := `
+		// leading comment
+		let x = 10 / 2; // trailing comment
+		//
+		let y = x // comment right after a name
+			* 3;
+		// comment at EOF`
+
+	uncommented := `
+		let x = 10 / 2;
+		let y = x
+			* 3;`
+
+	want := New(uncommented)
+	got := New(commented)
+
+	for i := 0; ; i++ {
+		wantTok := want.NextToken()
+		gotTok := got.NextToken()
+		if gotTok.Type != wantTok.Type || gotTok.Literal != wantTok.Literal {
+			t.Fatalf("tokens[%d] - expected=%+v, got=%+v", i, wantTok, gotTok)
+		}
+		if wantTok.Type == token.EOF {
+			break
+		}
+	}
+}
+
+func TestBlockCommentLexing(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		errors int
+	}{
+		{"let /* single line */ x = 1;", "let x = 1;", 0},
+		{"/*\n * multi\n * line\n */\nlet x = 1;", "let x = 1;", 0},
+		{"1 + /* inside */ 2 /**/ * 3", "1 + 2 * 3", 0},
+		{"1 /* not /* nested */ + 2", "1 + 2", 0},
+		{"let x = 1; /* unterminated\n let y = 2;", "let x = 1;", 1},
+	}
+
+	for i, tt := range tests {
+		want := New(tt.want)
+		got := New(tt.input)
+
+		for j := 0; ; j++ {
+			wantTok := want.NextToken()
+			gotTok := got.NextToken()
+			if gotTok.Type != wantTok.Type || gotTok.Literal != wantTok.Literal {
+				t.Fatalf("tests[%d] tokens[%d] - expected=%+v, got=%+v", i, j, wantTok, gotTok)
+			}
+			if wantTok.Type == token.EOF {
+				break
+			}
+		}
+
+		if n := len(got.Errors()); n != tt.errors {
+			t.Fatalf("tests[%d] - expected %d errors, got=%d %q", i, tt.errors, n, got.Errors())
+		}
+	}
+}
+
+func TestLexerPositionTracking(t *testing.T) {
+	input := "let x = 5;\n\tx + \"hi\"\n\n!= 10"
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+	}{
+		{token.LET, token.Position{Line: 1, Col: 1, Offset: 0}},
+		{token.NAME, token.Position{Line: 1, Col: 5, Offset: 4}},
+		{token.ASSIGN, token.Position{Line: 1, Col: 7, Offset: 6}},
+		{token.INT, token.Position{Line: 1, Col: 9, Offset: 8}},
+		{token.SEMICOLON, token.Position{Line: 1, Col: 10, Offset: 9}},
+		{token.NAME, token.Position{Line: 2, Col: 2, Offset: 12}},
+		{token.PLUS, token.Position{Line: 2, Col: 4, Offset: 14}},
+		{token.STRING, token.Position{Line: 2, Col: 6, Offset: 16}},
+		{token.NEQ, token.Position{Line: 4, Col: 1, Offset: 22}},
+		{token.INT, token.Position{Line: 4, Col: 4, Offset: 25}},
+		{token.EOF, token.Position{Line: 4, Col: 6, Offset: 27}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong token type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - wrong position. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+	}
+}
+
+func TestGTELTELexing(t *testing.T) {
+	assertTokens(t, "5 >= 3; x <= 10; a > b < c", []expectedToken{
+		{token.INT, "5"},
+		{token.GTE, ">="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "x"},
+		{token.LTE, "<="},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "a"},
+		{token.GT, ">"},
+		{token.NAME, "b"},
+		{token.LT, "<"},
+		{token.NAME, "c"},
+		{token.EOF, ""},
+	})
+}
+
+func TestLogicalOperatorLexing(t *testing.T) {
+	assertTokens(t, "a && b || c & d | e", []expectedToken{
+		{token.NAME, "a"},
+		{token.AND, "&&"},
+		{token.NAME, "b"},
+		{token.OR, "||"},
+		{token.NAME, "c"},
+		{token.ILLEGAL, "&"},
+		{token.NAME, "d"},
+		{token.ILLEGAL, "|"},
+		{token.NAME, "e"},
+		{token.EOF, ""},
+	})
+}
+
+func TestBracketTokens(t *testing.T) {
+	assertTokens(t, "arr[0]", []expectedToken{
+		{token.NAME, "arr"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.EOF, ""},
+	})
+
+	assertTokens(t, "[[1, 2], [3]]", []expectedToken{
+		{token.LBRACKET, "["},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.COMMA, ","},
+		{token.LBRACKET, "["},
+		{token.INT, "3"},
+		{token.RBRACKET, "]"},
+		{token.RBRACKET, "]"},
+		{token.EOF, ""},
+	})
+}
+
+func TestColonToken(t *testing.T) {
+	assertTokens(t, `{"a": 1, "b": 2}`, []expectedToken{
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.STRING, "b"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	})
+
+	assertTokens(t, "a : b", []expectedToken{
+		{token.NAME, "a"},
+		{token.COLON, ":"},
+		{token.NAME, "b"},
+		{token.EOF, ""},
+	})
+}
+
+func TestModuloLexing(t *testing.T) {
+	assertTokens(t, "10 % 3", []expectedToken{
+		{token.INT, "10"},
+		{token.PERCENT, "%"},
+		{token.INT, "3"},
+		{token.EOF, ""},
+	})
+}
+
+func TestPowerLexing(t *testing.T) {
+	assertTokens(t, "2 ** 3 * 4", []expectedToken{
+		{token.INT, "2"},
+		{token.POWER, "**"},
+		{token.INT, "3"},
+		{token.STAR, "*"},
+		{token.INT, "4"},
+		{token.EOF, ""},
+	})
+}
+
+func TestHexLiterals(t *testing.T) {
+	assertTokens(t, "0xFF + 0xdead0; 0X1f 0", []expectedToken{
+		{token.INT, "0xFF"},
+		{token.PLUS, "+"},
+		{token.INT, "0xdead0"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "0X1f"},
+		{token.INT, "0"},
+		{token.EOF, ""},
+	})
+}
+
+func TestOctalBinaryLiterals(t *testing.T) {
+	assertTokens(t, "0o7 0o17 0b101 0b11111111 0b2 0o8", []expectedToken{
+		{token.INT, "0o7"},
+		{token.INT, "0o17"},
+		{token.INT, "0b101"},
+		{token.INT, "0b11111111"},
+		{token.INT, "0b"}, // 2 is not a binary digit
+		{token.INT, "2"},
+		{token.INT, "0o"}, // 8 is not an octal digit
+		{token.INT, "8"},
+		{token.EOF, ""},
+	})
+}
+
+func TestIncrementDecrementLexing(t *testing.T) {
+	assertTokens(t, "++i; j--; a + -b", []expectedToken{
+		{token.INCREMENT, "++"},
+		{token.NAME, "i"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "j"},
+		{token.DECREMENT, "--"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "a"},
+		{token.PLUS, "+"},
+		{token.MINUS, "-"},
+		{token.NAME, "b"},
+		{token.EOF, ""},
+	})
+}
+
+func TestCompoundAssignmentLexing(t *testing.T) {
+	assertTokens(t, "a += 1; b -= 2; c *= 3; d /= 4; e ** f", []expectedToken{
+		{token.NAME, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "b"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "c"},
+		{token.STAR_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "d"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.NAME, "e"},
+		{token.POWER, "**"},
+		{token.NAME, "f"},
+		{token.EOF, ""},
+	})
+}
+
+func TestLexerPeek(t *testing.T) {
+	l := New("let x")
+
+	first := l.Peek()
+	second := l.Peek()
+	if first != second {
+		t.Fatalf("Peek() is not idempotent, first=%+v, second=%+v", first, second)
+	}
+	if first.Type != token.LET {
+		t.Fatalf("Peek() wrong token type. expected=%q, got=%q", token.LET, first.Type)
+	}
+
+	if tok := l.NextToken(); tok != first {
+		t.Fatalf("NextToken() did not return the peeked token, expected=%+v, got=%+v", first, tok)
+	}
+	if tok := l.NextToken(); tok.Type != token.NAME || tok.Literal != "x" {
+		t.Fatalf("NextToken() did not advance past the peeked token, got=%+v", tok)
+	}
+	if tok := l.Peek(); tok.Type != token.EOF {
+		t.Fatalf("Peek() wrong token type. expected=%q, got=%q", token.EOF, tok.Type)
+	}
+}
+
+func TestArrowToken(t *testing.T) {
+	assertTokens(t, "5 -> 10", []expectedToken{
+		{token.INT, "5"},
+		{token.ARROW, "->"},
+		{token.INT, "10"},
+		{token.EOF, ""},
+	})
+
+	assertTokens(t, "5 - 10 - -1", []expectedToken{
+		{token.INT, "5"},
+		{token.MINUS, "-"},
+		{token.INT, "10"},
+		{token.MINUS, "-"},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.EOF, ""},
+	})
+}
+
+func TestPipeToken(t *testing.T) {
+	assertTokens(t, "x |> f || y", []expectedToken{
+		{token.NAME, "x"},
+		{token.PIPE, "|>"},
+		{token.NAME, "f"},
+		{token.OR, "||"},
+		{token.NAME, "y"},
+		{token.EOF, ""},
+	})
+}
+
+func TestQuestionToken(t *testing.T) {
+	assertTokens(t, "a ? b : c", []expectedToken{
+		{token.NAME, "a"},
+		{token.QUESTION, "?"},
+		{token.NAME, "b"},
+		{token.COLON, ":"},
+		{token.NAME, "c"},
+		{token.EOF, ""},
+	})
+}
+
+func TestStringInterpolationLexing(t *testing.T) {
+	assertTokens(t, `f"Hello ${name}!"`, []expectedToken{
+		{token.STRING_PART, "Hello "},
+		{token.INTERP_START, "${"},
+		{token.NAME, "name"},
+		{token.INTERP_END, "}"},
+		{token.STRING_PART, "!"},
+		{token.EOF, ""},
+	})
+	assertTokens(t, `f"${1 + 2} items"`, []expectedToken{
+		{token.STRING_PART, ""},
+		{token.INTERP_START, "${"},
+		{token.INT, "1"},
+		{token.PLUS, "+"},
+		{token.INT, "2"},
+		{token.INTERP_END, "}"},
+		{token.STRING_PART, " items"},
+		{token.EOF, ""},
+	})
+	// braces inside an interpolation do not end it
+	assertTokens(t, `f"${fn(x){x}(5)} widgets"`, []expectedToken{
+		{token.STRING_PART, ""},
+		{token.INTERP_START, "${"},
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.NAME, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.NAME, "x"},
+		{token.RBRACE, "}"},
+		{token.LPAREN, "("},
+		{token.INT, "5"},
+		{token.RPAREN, ")"},
+		{token.INTERP_END, "}"},
+		{token.STRING_PART, " widgets"},
+		{token.EOF, ""},
+	})
+	assertTokens(t, `f"cost: \$5 ${f"${x}"}" $`, []expectedToken{
+		{token.STRING_PART, "cost: $5 "},
+		{token.INTERP_START, "${"},
+		{token.STRING_PART, ""},
+		{token.INTERP_START, "${"},
+		{token.NAME, "x"},
+		{token.INTERP_END, "}"},
+		{token.STRING_PART, ""},
+		{token.INTERP_END, "}"},
+		{token.STRING_PART, ""},
+		{token.ILLEGAL, "$"},
+		{token.EOF, ""},
+	})
+	assertTokens(t, `f"never ${x} closed`, []expectedToken{
+		{token.STRING_PART, "never "},
+		{token.INTERP_START, "${"},
+		{token.NAME, "x"},
+		{token.INTERP_END, "}"},
+		{token.ILLEGAL, `f" closed`},
+		{token.EOF, ""},
+	})
+}
+
+func TestTokensDump(t *testing.T) {
+	var out bytes.Buffer
+	DumpTokens(New("let x = 5;\nputs(\"hi\");"), &out)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("wrong number of lines, got=%d:\n%s", len(lines), out.String())
+	}
+
+	want := map[int]string{
+		0:  `LET  "let"  1:1`,
+		1:  `NAME  "x"  1:5`,
+		3:  `INT  "5"  1:9`,
+		7:  `STRING  "hi"  2:6`,
+		10: `EOF  ""  2:12`,
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("lines[%d] is wrong, got=%q, want=%q", i, lines[i], line)
+		}
+	}
+}
+
+func TestCommentsKept(t *testing.T) {
+	input := "// leading\nlet x = 1; // trailing\n/* block\n   comment */ let y = /* inline */ 2;\n"
+	l := New(input)
+	for l.NextToken().Type != token.EOF {
+	}
+
+	want := []token.Comment{
+		{Text: "// leading", Pos: token.Position{Line: 1, Col: 1, Offset: 0}},
+		{Text: "// trailing", Pos: token.Position{Line: 2, Col: 12, Offset: 22}, Trailing: true},
+		{Text: "/* block\n   comment */", Pos: token.Position{Line: 3, Col: 1, Offset: 34}},
+		{Text: "/* inline */", Pos: token.Position{Line: 4, Col: 23, Offset: 65}, Trailing: true},
+	}
+
+	got := l.Comments()
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of comments, got=%d: %+v", len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("comments[%d] is wrong, got=%+v, want=%+v", i, got[i], want[i])
+		}
+	}
+}
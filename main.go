@@ -1,20 +1,10 @@
 package main
 
 import (
-	"fmt"
-	"monkey/repl"
+	"monkey/cli"
 	"os"
-	"os/user"
 )
 
 func main() {
-	user, err := user.Current()
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Printf("Hello %s!\n", user.Username)
-	fmt.Printf("Type in some Monkeylang commands\n")
-
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
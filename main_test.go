@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monkey/test"
+)
+
+// binary is the monkey command, built once for the tests that run it.
+var binary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "monkey")
+	if err != nil {
+		panic(err)
+	}
+
+	binary = filepath.Join(dir, "monkey")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		panic(string(out))
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runMonkey runs the monkey command with args, returning its stdout, stderr
+// and exit code.
+func runMonkey(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running %v failed: %s", args, err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestEvalFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{[]string{"-e", "5 * 5"}, "25\n", "", 0},
+		{[]string{"--eval", `"a" + "b"`}, "ab\n", "", 0},
+		{[]string{"-e", "puts(1 + 2)"}, "3\n", "", 0},
+		{[]string{"-e", "let x = ;"}, "", "1:9: no prefix parse function for ; found\n", 1},
+		{[]string{"-e", "1 + true"}, "", "ERROR: type mismatch: INTEGER + BOOLEAN\n", 1},
+	}
+
+	for _, tt := range tests {
+		stdout, stderr, code := runMonkey(t, tt.args...)
+		test.AssertEqual(t, stdout, tt.stdout)
+		test.AssertEqual(t, stderr, tt.stderr)
+		test.AssertEqual(t, code, tt.code)
+	}
+}
+
+func TestASTFlag(t *testing.T) {
+	stdout, stderr, code := runMonkey(t, "--ast", "testdata/add.mk")
+	test.AssertEqual(t, code, 0)
+	test.AssertEqual(t, stderr, "")
+	for _, want := range []string{"Program\n", "    LetStatement\n", "        FunctionLiteral\n", "InfixExpression +\n", "CallExpression\n", "IntegerLiteral 2\n"} {
+		test.AssertTrue(t, strings.Contains(stdout, want))
+	}
+
+	stdout, _, code = runMonkey(t, "--ast-json", "testdata/add.mk")
+	test.AssertEqual(t, code, 0)
+	test.AssertTrue(t, strings.Contains(stdout, `"type": "FunctionLiteral"`))
+	test.AssertTrue(t, json.Valid([]byte(stdout)))
+
+	stdout, stderr, code = runMonkey(t, "--ast", "testdata/broken.mk")
+	test.AssertEqual(t, code, 1)
+	test.AssertEqual(t, stdout, "")
+	test.AssertEqual(t, stderr, "testdata/broken.mk:1:9: no prefix parse function for ; found\n")
+}
+
+func TestRunFile(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{[]string{"testdata/four.mk"}, "4\n", "", 0},
+		{[]string{"testdata/add.mk"}, "3\n", "", 0},
+		// imports are relative to the file, and an error ends the program
+		{[]string{"testdata/failing.mk"}, "9\n", "ERROR: type mismatch: INTEGER + BOOLEAN\n", 1},
+		{[]string{"testdata/broken.mk"}, "", "testdata/broken.mk:1:9: no prefix parse function for ; found\n", 1},
+		{[]string{"testdata/missing.mk"}, "", "open testdata/missing.mk: no such file or directory\n", 1},
+		{[]string{"testdata/four.mk", "testdata/add.mk"}, "", "expected a single file\n", 2},
+		{[]string{"-e", ";"}, "", "", 0},
+	}
+
+	for _, tt := range tests {
+		stdout, stderr, code := runMonkey(t, tt.args...)
+		test.AssertEqual(t, stdout, tt.stdout)
+		test.AssertEqual(t, stderr, tt.stderr)
+		test.AssertEqual(t, code, tt.code)
+	}
+}
+
+func TestMonkeyFmt(t *testing.T) {
+	want := `let max = fn (a, b) {
+    if (a > b) {
+        a;
+    } else {
+        b;
+    }
+};
+let nums = [3, 1, 2];
+let total = 0;
+for (let i = 0; i < len(nums); i += 1) {
+    total += nums[i] * (i + 1);
+}
+puts(max(total, 10));
+`
+
+	stdout, stderr, code := runMonkey(t, "fmt", "testdata/unformatted.mk")
+	test.AssertEqual(t, stdout, want)
+	test.AssertEqual(t, stderr, "")
+	test.AssertEqual(t, code, 0)
+
+	// -w rewrites the file in place, and formatting is idempotent
+	path := filepath.Join(t.TempDir(), "script.mk")
+	source, err := os.ReadFile("testdata/unformatted.mk")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, os.WriteFile(path, source, 0o644), nil)
+
+	stdout, _, code = runMonkey(t, "fmt", "-w", path)
+	test.AssertEqual(t, stdout, "")
+	test.AssertEqual(t, code, 0)
+	formatted, err := os.ReadFile(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(formatted), want)
+
+	stdout, _, _ = runMonkey(t, "fmt", path)
+	test.AssertEqual(t, stdout, want)
+
+	// comments are kept where they were
+	commented := createScript(t, "// square a number\nlet sq=fn(x){x*x} /* note */\nputs(sq(3)) // 9\n")
+	want = "// square a number\nlet sq = fn (x) {\n    x * x;\n}; /* note */\nputs(sq(3)); // 9\n"
+
+	stdout, _, code = runMonkey(t, "fmt", commented)
+	test.AssertEqual(t, stdout, want)
+	test.AssertEqual(t, code, 0)
+
+	_, _, code = runMonkey(t, "fmt", "-w", commented)
+	test.AssertEqual(t, code, 0)
+	formatted, err = os.ReadFile(commented)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(formatted), want)
+
+	_, stderr, code = runMonkey(t, "fmt", "testdata/broken.mk")
+	test.AssertEqual(t, stderr, "testdata/broken.mk:1:9: no prefix parse function for ; found\n")
+	test.AssertEqual(t, code, 1)
+}
+
+// createScript writes source to a new .mk file in a temporary directory, and
+// returns its path.
+func createScript(t *testing.T, source string) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "*.mk")
+	if err != nil {
+		t.Fatalf("creating script failed: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(source); err != nil {
+		t.Fatalf("writing script failed: %s", err)
+	}
+	return f.Name()
+}
+
+func TestMonkeyCheck(t *testing.T) {
+	good := createScript(t, "let x = 1;\nputs(x);\n")
+	bad := createScript(t, "let x = 1;\n\tlet y = ;\nlet = 2;\n")
+
+	stdout, stderr, code := runMonkey(t, "check", good)
+	test.AssertEqual(t, stdout, "OK: "+good+" (2 statements)\n")
+	test.AssertEqual(t, stderr, "")
+	test.AssertEqual(t, code, 0)
+
+	stdout, stderr, code = runMonkey(t, "check", good, bad)
+	test.AssertEqual(t, stdout, "OK: "+good+" (2 statements)\n")
+	test.AssertEqual(t, code, 1)
+	want := bad + ":2:10: no prefix parse function for ; found\n" +
+		"    \tlet y = ;\n" +
+		"    \t        ^\n" +
+		bad + ":3:1: expected next token to be 'NAME', got '=' parsing: 'let ...'\n" +
+		"    let = 2;\n" +
+		"    ^\n"
+	test.AssertEqual(t, stderr, want)
+}
@@ -0,0 +1,109 @@
+package object
+
+import (
+	"sort"
+	"strings"
+)
+
+// Environment holds the values bound to names while a program is evaluated.
+// Names not bound in an environment are looked up in its outer one.
+type Environment struct {
+	store       map[string]Object
+	accessCount map[string]int // number of times Get found each name here
+	outer       *Environment
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object), accessCount: make(map[string]int)}
+}
+
+// NewEnclosedEnvironment creates an environment for a new scope, e.g. a
+// function call, that can see the names bound in outer.
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// Get looks name up in env and then in its outer environments, and counts the
+// access in the environment it is found in.
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if ok {
+		e.accessCount[name]++
+	} else if e.outer != nil {
+		obj, ok = e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// AccessCount returns how many times Get found name bound in env itself.
+func (e *Environment) AccessCount(name string) int {
+	return e.accessCount[name]
+}
+
+// Set binds name to val in env itself, shadowing any outer binding.
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Assign rebinds name in the environment it is bound in, which may be an outer
+// one. It reports false, binding nothing, if name is not bound at all.
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns a copy of env and its outer environments, whose bindings can
+// then change independently of env's.
+func (e *Environment) Copy() *Environment {
+	env := &Environment{store: make(map[string]Object, len(e.store)), accessCount: make(map[string]int)}
+	for name, val := range e.store {
+		env.store[name] = val
+	}
+	if e.outer != nil {
+		env.outer = e.outer.Copy()
+	}
+	return env
+}
+
+// Outer returns the environment env was enclosed in, or nil if there is none.
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// Names returns the names bound in env itself, not in its outer environments,
+// in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String lists the bindings in env, one name: value line each, sorted by name.
+// Those of the outer environments follow an -- outer scope -- line, indented.
+func (e *Environment) String() string {
+	var out strings.Builder
+	for _, name := range e.Names() {
+		out.WriteString(name + ": " + e.store[name].Inspect() + "\n")
+	}
+
+	if e.outer != nil {
+		out.WriteString("-- outer scope --\n")
+		for _, line := range strings.SplitAfter(e.outer.String(), "\n") {
+			if line != "" {
+				out.WriteString("    " + line)
+			}
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,110 @@
+package object
+
+import (
+	"monkey/test"
+	"strings"
+	"testing"
+)
+
+func TestEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	outer.Set("y", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &Integer{Value: 3})
+
+	x, ok := inner.Get("x")
+	test.AssertTrue(t, ok)
+	test.AssertEqual(t, x.Inspect(), "1")
+
+	y, ok := inner.Get("y")
+	test.AssertTrue(t, ok)
+	test.AssertEqual(t, y.Inspect(), "3")
+
+	// shadowing in the inner scope leaves the outer binding alone
+	y, _ = outer.Get("y")
+	test.AssertEqual(t, y.Inspect(), "2")
+
+	_, ok = inner.Get("z")
+	test.AssertFalse(t, ok)
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+
+	// assigning in the inner scope updates the outer binding
+	test.AssertTrue(t, inner.Assign("x", &Integer{Value: 2}))
+	x, _ := outer.Get("x")
+	test.AssertEqual(t, x.Inspect(), "2")
+
+	test.AssertFalse(t, inner.Assign("z", &Integer{Value: 3}))
+	_, ok := inner.Get("z")
+	test.AssertFalse(t, ok)
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("a", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("c", &Integer{Value: 2})
+	inner.Set("b", &Integer{Value: 3})
+
+	test.AssertEqual(t, strings.Join(inner.Names(), ","), "b,c")
+	test.AssertEqual(t, len(NewEnvironment().Names()), 0)
+}
+
+func TestEnvironmentCopy(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &Integer{Value: 2})
+
+	copied := inner.Copy()
+	test.AssertTrue(t, copied.Assign("x", &Integer{Value: 3}))
+	inner.Set("y", &Integer{Value: 4})
+
+	x, _ := outer.Get("x")
+	test.AssertEqual(t, x.Inspect(), "1")
+	x, _ = copied.Get("x")
+	test.AssertEqual(t, x.Inspect(), "3")
+	y, _ := copied.Get("y")
+	test.AssertEqual(t, y.Inspect(), "2")
+}
+
+func TestEnvironmentString(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 0})
+	env := NewEnclosedEnvironment(outer)
+	env.Set("charlie", &Integer{Value: 3})
+	env.Set("alpha", &Integer{Value: 1})
+	env.Set("bravo", &String{Value: "two"})
+
+	got := env.String()
+	alpha, bravo, charlie := strings.Index(got, "alpha"), strings.Index(got, "bravo"), strings.Index(got, "charlie")
+	test.AssertTrue(t, alpha >= 0 && alpha < bravo && bravo < charlie)
+
+	want := "alpha: 1\nbravo: two\ncharlie: 3\n-- outer scope --\n    x: 0\n"
+	test.AssertEqual(t, got, want)
+	test.AssertEqual(t, NewEnvironment().String(), "")
+}
+
+func TestEnvironmentAccessCount(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &Integer{Value: 2})
+
+	inner.Get("x")
+	inner.Get("x")
+	inner.Get("y")
+	inner.Get("z")
+
+	// accesses are counted where the name is bound
+	test.AssertEqual(t, outer.AccessCount("x"), 2)
+	test.AssertEqual(t, inner.AccessCount("x"), 0)
+	test.AssertEqual(t, inner.AccessCount("y"), 1)
+	test.AssertEqual(t, inner.AccessCount("z"), 0)
+}
@@ -0,0 +1,297 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"monkey/ast"
+	"strconv"
+	"strings"
+)
+
+type ObjectType string
+
+const (
+	INTEGER_OBJ  = "INTEGER"
+	FLOAT_OBJ    = "FLOAT"
+	BOOLEAN_OBJ  = "BOOLEAN"
+	STRING_OBJ   = "STRING"
+	ARRAY_OBJ    = "ARRAY"
+	HASH_OBJ     = "HASH"
+	NULL_OBJ     = "NULL"
+	ERROR_OBJ    = "ERROR"
+	FUNCTION_OBJ = "FUNCTION"
+	BUILTIN_OBJ  = "BUILTIN"
+	MODULE_OBJ   = "MODULE"
+
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+)
+
+// There is only ever one true, false and null, so they can be compared by
+// pointer.
+var (
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+	NULL  = &Null{}
+)
+
+// Object is every value a Monkey program can produce.
+type Object interface {
+	Type() ObjectType
+	// Inspect renders the value for the REPL and for debugging
+	Inspect() string
+}
+
+// Hashable is implemented by the objects that can be used as hash keys.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+// HashKey identifies a key in a Hash: equal keys have equal HashKeys.
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Integer
+// -----------------------------------------------------------------------------
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+// Float
+// -----------------------------------------------------------------------------
+
+type Float struct {
+	Value float64
+}
+
+// NewFloat returns a Float holding v.
+func NewFloat(v float64) *Float {
+	return &Float{Value: v}
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect renders f in decimal notation, unless its absolute value is at
+// least 1e21 or is nonzero and below 1e-6, which are rendered in exponent
+// notation, e.g. 1e+21 or 1e-07. 1e20 is thus still written out in 21
+// digits. The lexer has no exponent literals, so the exponent notation cannot
+// be read back as source.
+func (f *Float) Inspect() string {
+	if abs := math.Abs(f.Value); abs >= 1e21 || abs != 0 && abs < 1e-6 {
+		return strconv.FormatFloat(f.Value, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f.Value, 'f', -1, 64)
+}
+
+// HashKey is the bits of f, with -0 hashed as 0 since they are equal.
+func (f *Float) HashKey() HashKey {
+	if f.Value == 0 {
+		return HashKey{Type: f.Type(), Value: 0}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
+// Boolean
+// -----------------------------------------------------------------------------
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+// String
+// -----------------------------------------------------------------------------
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// Array
+// -----------------------------------------------------------------------------
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	elements := []string{}
+	for _, el := range a.Elements {
+		elements = append(elements, el.Inspect())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// Hash
+// -----------------------------------------------------------------------------
+
+// HashPair keeps the original key of a hash entry, for Inspect and iteration.
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps keys to values. Its pairs are added with Set, which keeps track
+// of the order they were added in.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+
+	insertionOrder []HashKey // keys of Pairs, in the order they were added
+}
+
+// NewHash returns an empty Hash.
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+// Set stores pair under key. A new key goes after the others, while an
+// existing key keeps its place.
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.insertionOrder = append(h.insertionOrder, key)
+	}
+	h.Pairs[key] = pair
+}
+
+// Inspect renders the pairs in the order they were added.
+func (h *Hash) Inspect() string {
+	pairs := make([]string, len(h.insertionOrder))
+	for i, key := range h.insertionOrder {
+		pair := h.Pairs[key]
+		pairs[i] = pair.Key.Inspect() + ": " + pair.Value.Inspect()
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Null
+// -----------------------------------------------------------------------------
+
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// Builtin
+// -----------------------------------------------------------------------------
+
+// BuiltinFunction is a function provided by the interpreter, written in Go.
+type BuiltinFunction func(args ...Object) Object
+
+type Builtin struct {
+	Fn BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function" }
+
+// Return Value
+// -----------------------------------------------------------------------------
+
+// ReturnValue wraps the value of a return statement while it is passed up
+// through the enclosing blocks, to the function call or program it ends.
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Break and Continue Signals
+// -----------------------------------------------------------------------------
+
+// BreakSignal is passed up through the enclosing blocks by a break statement,
+// to the loop it ends.
+type BreakSignal struct{}
+
+func (bs *BreakSignal) Type() ObjectType { return BREAK_OBJ }
+func (bs *BreakSignal) Inspect() string  { return "break" }
+
+// ContinueSignal is passed up through the enclosing blocks by a continue
+// statement, to the loop whose next iteration it starts.
+type ContinueSignal struct{}
+
+func (cs *ContinueSignal) Type() ObjectType { return CONTINUE_OBJ }
+func (cs *ContinueSignal) Inspect() string  { return "continue" }
+
+// Error
+// -----------------------------------------------------------------------------
+
+// Error is a runtime error. It is a value so that it can be returned up
+// through the evaluation of a program.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// Function
+// -----------------------------------------------------------------------------
+
+// Function is a function literal closed over the environment it was
+// evaluated in.
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+
+// Module
+// -----------------------------------------------------------------------------
+
+// Module is an imported source file, with the names bound at its top level.
+type Module struct {
+	Name string
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name }
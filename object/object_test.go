@@ -0,0 +1,99 @@
+package object
+
+import (
+	"math"
+	"monkey/test"
+	"testing"
+)
+
+func TestObjectInspect(t *testing.T) {
+	tests := []struct {
+		obj  Object
+		want string
+	}{
+		{&Integer{Value: 42}, "42"},
+		{&Integer{Value: -7}, "-7"},
+		{&String{Value: "hello"}, "hello"},
+		{TRUE, "true"},
+		{FALSE, "false"},
+		{NULL, "null"},
+		{&Error{Message: "division by zero"}, "ERROR: division by zero"},
+	}
+
+	for _, tt := range tests {
+		test.AssertEqual(t, tt.obj.Inspect(), tt.want)
+	}
+}
+
+func TestObjectType(t *testing.T) {
+	tests := []struct {
+		obj  Object
+		want ObjectType
+	}{
+		{&Integer{Value: 42}, INTEGER_OBJ},
+		{&String{Value: "hello"}, STRING_OBJ},
+		{TRUE, BOOLEAN_OBJ},
+		{NULL, NULL_OBJ},
+		{&Error{}, ERROR_OBJ},
+		{&BreakSignal{}, BREAK_OBJ},
+		{&ContinueSignal{}, CONTINUE_OBJ},
+		{&Module{Name: "math", Env: NewEnvironment()}, MODULE_OBJ},
+	}
+
+	for _, tt := range tests {
+		test.AssertEqual(t, tt.obj.Type(), tt.want)
+	}
+}
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	test.AssertEqual(t, hello1.HashKey(), hello2.HashKey())
+	test.AssertEqual(t, diff1.HashKey(), diff2.HashKey())
+	test.AssertNotEqual(t, hello1.HashKey(), diff1.HashKey())
+}
+
+func TestHashKeyTypes(t *testing.T) {
+	// keys of different types never collide, even with equal values
+	test.AssertNotEqual(t, (&Integer{Value: 1}).HashKey(), TRUE.HashKey())
+	test.AssertEqual(t, (&Integer{Value: 1}).HashKey(), (&Integer{Value: 1}).HashKey())
+	test.AssertEqual(t, FALSE.HashKey(), (&Boolean{Value: false}).HashKey())
+}
+
+func TestFloatObject(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{1.5, "1.5"},
+		{0.0, "0"},
+		{-3.14, "-3.14"},
+		{1e10, "10000000000"},
+		{1e21, "1e+21"},
+		{-2.5e-7, "-2.5e-07"},
+	}
+
+	for _, tt := range tests {
+		test.AssertEqual(t, NewFloat(tt.value).Inspect(), tt.want)
+	}
+
+	test.AssertEqual(t, NewFloat(2.5).HashKey(), NewFloat(2.5).HashKey())
+	test.AssertEqual(t, NewFloat(0).HashKey(), NewFloat(math.Copysign(0, -1)).HashKey())
+	test.AssertNotEqual(t, NewFloat(2.5).HashKey(), NewFloat(2.25).HashKey())
+	// 1.0 is not the same key as 1
+	test.AssertNotEqual(t, NewFloat(1).HashKey(), (&Integer{Value: 1}).HashKey())
+}
+
+func TestHashInsertionOrder(t *testing.T) {
+	h := NewHash()
+	for _, key := range []string{"zebra", "apple", "mango", "apple"} {
+		str := &String{Value: key}
+		h.Set(str.HashKey(), HashPair{Key: str, Value: &Integer{Value: int64(len(key))}})
+	}
+
+	test.AssertEqual(t, h.Inspect(), "{zebra: 5, apple: 5, mango: 5}")
+	test.AssertEqual(t, NewHash().Inspect(), "{}")
+}
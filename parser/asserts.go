@@ -29,7 +29,18 @@ func assertIntegerLiteral(t *testing.T, il ast.Expression, value int64) {
 	}
 
 	test.AssertEqual(t, integ.Value, value)
-	test.AssertEqual(t, integ.TokenLiteral(), fmt.Sprintf("%d", value))
+	test.AssertEqual(t, integ.FirstTokenLiteral(), fmt.Sprintf("%d", value))
+}
+
+func assertFloatLiteral(t *testing.T, fl ast.Expression, value float64) {
+	t.Helper()
+	lit, ok := fl.(*ast.FloatLiteral)
+	if !ok {
+		t.Errorf("fl not *ast.FloatLiteral, got=%T", fl)
+		return
+	}
+
+	test.AssertEqual(t, lit.Value, value)
 }
 
 func assertBoolLiteral(t *testing.T, il ast.Expression, value bool) {
@@ -41,7 +52,7 @@ func assertBoolLiteral(t *testing.T, il ast.Expression, value bool) {
 	}
 
 	test.AssertEqual(t, lit.Value, value)
-	test.AssertEqual(t, lit.TokenLiteral(), fmt.Sprintf("%v", value))
+	test.AssertEqual(t, lit.FirstTokenLiteral(), fmt.Sprintf("%v", value))
 }
 
 func assertProgramNotNil(t *testing.T, program *ast.Program) {
@@ -59,15 +70,16 @@ func assertProgramStatements(t *testing.T, program *ast.Program, want int) {
 }
 
 func assertLetStatement(t *testing.T, s ast.Statement, name string) {
-	test.AssertEqual(t, s.TokenLiteral(), "let")
+	t.Helper()
+	test.AssertEqual(t, s.FirstTokenLiteral(), "let")
 
 	letStatement, ok := s.(*ast.LetStatement)
 	if !ok {
-		t.Errorf("s not *ast.LetStatement, got=%T", s)
+		t.Fatalf("s not *ast.LetStatement, got=%T", s)
 	}
 
 	test.AssertEqual(t, letStatement.Name.Value, name)
-	test.AssertEqual(t, letStatement.Name.TokenLiteral(), name)
+	test.AssertEqual(t, letStatement.Name.FirstTokenLiteral(), name)
 }
 
 func assertParserNoErrors(t *testing.T, p *Parser) {
@@ -101,8 +113,8 @@ func assertIdentifier(t *testing.T, exp ast.Expression, want string) {
 		t.Fatalf("ident.Value is not %s, got=%s", want, ident.Value)
 	}
 
-	if ident.TokenLiteral() != want {
-		t.Fatalf("ident.TokenLiteral is not %s, got=%s", want, ident.TokenLiteral())
+	if ident.FirstTokenLiteral() != want {
+		t.Fatalf("ident.FirstTokenLiteral is not %s, got=%s", want, ident.FirstTokenLiteral())
 	}
 }
 
@@ -115,6 +127,8 @@ func assertLiteralExpression(t *testing.T, exp ast.Expression, expected interfac
 		assertIntegerLiteral(t, exp, int64(v))
 	case int64:
 		assertIntegerLiteral(t, exp, v)
+	case float64:
+		assertFloatLiteral(t, exp, v)
 	case string:
 		assertIdentifier(t, exp, v)
 	default:
@@ -141,3 +155,17 @@ func assertInfixExpression(
 	}
 	assertLiteralExpression(t, opExp.Right, right)
 }
+
+// assertNodeString checks the String() of an optional node, where "" stands
+// for a nil node.
+func assertNodeString(t *testing.T, node ast.Node, want string) {
+	t.Helper()
+	if node == nil {
+		if want != "" {
+			t.Fatalf("node is nil, want %q", want)
+		}
+		return
+	}
+
+	test.AssertEqual(t, node.String(), want)
+}
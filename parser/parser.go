@@ -13,24 +13,49 @@ import (
 const (
 	_ int = iota
 	LOWEST
-	EQUALS  // ==
-	LTGT    // < or >
-	SUM     // +
-	PRODUCT // *
-	PREFIX  // -X or !X
-	CALL    // someFunction(X)
+	PIPE_PREC // |>
+	TERNARY   // ? :
+	OR_PREC   // ||
+	AND_PREC  // &&
+	EQUALS    // ==
+	LTGT      // <, >, <= or >=
+	SUM       // +
+	PRODUCT   // *, / or %
+	POWER     // **
+	PREFIX    // -X or !X
+	CALL      // someFunction(X)
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:     EQUALS,
-	token.NEQ:    EQUALS,
-	token.LT:     LTGT,
-	token.GT:     LTGT,
-	token.PLUS:   SUM,
-	token.MINUS:  SUM,
-	token.STAR:   PRODUCT,
-	token.SLASH:  PRODUCT,
-	token.LPAREN: CALL,
+	token.PIPE:      PIPE_PREC,
+	token.QUESTION:  TERNARY,
+	token.OR:        OR_PREC,
+	token.AND:       AND_PREC,
+	token.EQ:        EQUALS,
+	token.NEQ:       EQUALS,
+	token.LT:        LTGT,
+	token.GT:        LTGT,
+	token.LTE:       LTGT,
+	token.GTE:       LTGT,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.STAR:      PRODUCT,
+	token.SLASH:     PRODUCT,
+	token.PERCENT:   PRODUCT,
+	token.POWER:     POWER,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  CALL,
+	token.INCREMENT: CALL,
+	token.DECREMENT: CALL,
+	token.ARROW:     CALL,
+}
+
+// compoundAssignments are the operators that update a name in place.
+var compoundAssignments = map[token.TokenType]bool{
+	token.PLUS_ASSIGN:  true,
+	token.MINUS_ASSIGN: true,
+	token.STAR_ASSIGN:  true,
+	token.SLASH_ASSIGN: true,
 }
 
 type (
@@ -39,10 +64,24 @@ type (
 	infixParseFn func(ast.Expression) ast.Expression
 )
 
+// ParseError is a problem found in the input, at the position it was found.
+// Problems reported by the lexer have no position.
+type ParseError struct {
+	Message string
+	Pos     token.Position
+}
+
+func (e ParseError) Error() string {
+	if e.Pos == (token.Position{}) {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
 type Parser struct {
 	l *lexer.Lexer
 
-	errors   []string // TODO: extend to add row/col
+	errors   []ParseError
 	progress []string // literal progress of what is being parsed at the moment
 
 	currToken token.Token
@@ -64,30 +103,50 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []ParseError{},
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.NAME, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.STRING_PART, p.parseInterpolatedString)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolLiteral)
 	p.registerPrefix(token.FALSE, p.parseBoolLiteral)
+	p.registerPrefix(token.NULL, p.parseNullLiteral)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.INCREMENT, p.parsePrefixIncrement)
+	p.registerPrefix(token.DECREMENT, p.parsePrefixIncrement)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.STAR, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseRightInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LTE, p.parseInfixExpression)
+	p.registerInfix(token.GTE, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixIncrement)
+	p.registerInfix(token.DECREMENT, p.parsePostfixIncrement)
+	p.registerInfix(token.ARROW, p.parseReservedInfix)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
+	p.registerInfix(token.QUESTION, p.parseTernaryExpression)
 
 	// read two tokens, so currToken and peekToken are both set
 	p.advance()
@@ -96,10 +155,25 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []ParseError {
 	return p.errors
 }
 
+// ParseErrors returns the same errors as Errors, for callers that work with
+// the error interface.
+func (p *Parser) ParseErrors() []error {
+	errs := make([]error, len(p.errors))
+	for i, err := range p.errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// addError records a problem found at pos.
+func (p *Parser) addError(pos token.Position, format string, args ...interface{}) {
+	p.errors = append(p.errors, ParseError{Message: fmt.Sprintf(format, args...), Pos: pos})
+}
+
 func (p *Parser) Progress() string {
 	return strings.Join(p.progress, " ")
 }
@@ -129,17 +203,66 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.advance()
 	}
 
+	for _, msg := range p.l.Errors() {
+		p.errors = append(p.errors, ParseError{Message: msg})
+	}
+	program.HasErrors = len(p.errors) > 0
+	program.Comments = p.l.Comments()
+
 	return program
 }
 
 func (p *Parser) parseStatement() ast.Statement {
+	var stmt ast.Statement
+
 	switch p.currToken.Type {
+	case token.SEMICOLON:
+		// empty statement, e.g. the second ; in "let x = 5;;"
+		return nil
 	case token.LET:
-		return p.parseLetStatement()
+		stmt = p.parseLetStatement()
 	case token.RETURN:
-		return p.parseReturnStatement()
+		stmt = p.parseReturnStatement()
+	case token.FOR:
+		stmt = p.parseForStatement()
+	case token.WHILE:
+		stmt = p.parseWhileStatement()
+	case token.IMPORT:
+		stmt = p.parseImportStatement()
+	case token.BREAK:
+		stmt = p.parseBreakStatement()
+	case token.CONTINUE:
+		stmt = p.parseContinueStatement()
+	case token.NAME:
+		if p.nextTokenIs(token.ASSIGN) {
+			stmt = p.parseAssignStatement()
+		} else if compoundAssignments[p.peekToken.Type] {
+			stmt = p.parseCompoundAssignStatement()
+		} else {
+			stmt = p.parseExpressionStatement()
+		}
 	default:
-		return p.parseExpressionStatement()
+		stmt = p.parseExpressionStatement()
+	}
+
+	if stmt == nil {
+		p.synchronize()
+	}
+
+	return stmt
+}
+
+// synchronize skips the rest of a statement that failed to parse, so that
+// parsing resumes at the next statement and its errors are reported too. It
+// stops on a ';', or before a token that can start a new statement. A '}' is
+// a stopping point as well, so that the block being parsed is still closed.
+func (p *Parser) synchronize() {
+	for !p.currTokenIs(token.SEMICOLON) && !p.currTokenIs(token.EOF) {
+		switch p.peekToken.Type {
+		case token.LET, token.RETURN, token.IF, token.FUNCTION, token.RBRACE, token.EOF:
+			return
+		}
+		p.advance()
 	}
 }
 
@@ -147,7 +270,7 @@ func (p *Parser) parseStatement() ast.Statement {
 // -----------------------------------------------------------------------------
 
 // let x = 5;
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
 	stmt := &ast.LetStatement{Token: p.currToken}
 
 	// ensure next token is identifier and advance
@@ -169,7 +292,7 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
-	for p.nextTokenIs(token.SEMICOLON) {
+	if p.nextTokenIs(token.SEMICOLON) {
 		p.advance()
 	}
 
@@ -184,19 +307,198 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for p.nextTokenIs(token.SEMICOLON) {
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// import "stdlib/math";
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.currToken}
+
+	if !p.advanceIfNextTokenIs(token.STRING) {
+		return nil
+	}
+
+	stmt.Path = &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
+
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// while (x > 0) { ... }
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.currToken}
+
+	if !p.advanceIfNextTokenIs(token.LPAREN) {
+		return nil
+	}
+
+	p.advance()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.advanceIfNextTokenIs(token.RPAREN) {
+		return nil
+	}
+
+	if !p.advanceIfNextTokenIs(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// break;
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.currToken}
+
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// continue;
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.currToken}
+
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// for (let i = 0; i < 10; i += 1) { ... }
+//
+// Every clause is optional, so "for (;;) { ... }" loops forever.
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.currToken}
+
+	if !p.advanceIfNextTokenIs(token.LPAREN) {
+		return nil
+	}
+
+	// for ( let i = 0; ...
+	//       ^
+	p.advance()
+	if !p.currTokenIs(token.SEMICOLON) {
+		if stmt.Init = p.parseStatement(); stmt.Init == nil {
+			return nil
+		}
+
+		// init statements consume their own semicolon
+		if !p.currTokenIs(token.SEMICOLON) {
+			p.peekError(token.SEMICOLON)
+			return nil
+		}
+	}
+
+	// for ( ...; i < 10; ...
+	//          ^
+	if !p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.advanceIfNextTokenIs(token.SEMICOLON) {
+		return nil
+	}
+
+	// for ( ...; ...; i += 1 )
+	//               ^
+	if !p.nextTokenIs(token.RPAREN) {
+		p.advance()
+		if stmt.Post = p.parseStatement(); stmt.Post == nil {
+			return nil
+		}
+	}
+
+	if !p.advanceIfNextTokenIs(token.RPAREN) {
+		return nil
+	}
+
+	if !p.advanceIfNextTokenIs(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+// x = 10;
+func (p *Parser) parseAssignStatement() *ast.AssignStatement {
+	stmt := &ast.AssignStatement{
+		Token: p.currToken,
+		Name:  &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal},
+	}
+
+	p.advance() // =
+	p.advance()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+// arr[0] = 5;
+func (p *Parser) parseIndexAssignStatement(first token.Token, target *ast.IndexExpression) *ast.IndexAssignStatement {
+	stmt := &ast.IndexAssignStatement{Token: first, Target: target}
+
+	p.advance() // =
+	p.advance()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.nextTokenIs(token.SEMICOLON) {
 		p.advance()
 	}
 
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+// x += 5;
+func (p *Parser) parseCompoundAssignStatement() *ast.CompoundAssignStatement {
+	stmt := &ast.CompoundAssignStatement{
+		Token: p.currToken,
+		Name:  &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal},
+	}
+
+	p.advance()
+	stmt.Operator = p.currToken.Literal
+
+	p.advance()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.nextTokenIs(token.SEMICOLON) {
+		p.advance()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	// defer untrace(trace("parseExpressionStatement"))
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	// an index can only be told apart from an index assignment once parsed
+	if index, ok := stmt.Expression.(*ast.IndexExpression); ok && p.nextTokenIs(token.ASSIGN) {
+		return p.parseIndexAssignStatement(stmt.Token, index)
+	}
+
 	// we want expression statements to have optional semicolons, which makes
 	// it easier to type in REPL
 	if p.nextTokenIs(token.SEMICOLON) {
@@ -253,14 +555,31 @@ func (p *Parser) parseBoolLiteral() ast.Expression {
 	}
 }
 
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.currToken}
+}
+
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	// defer untrace(trace("parseIntegerLiteral"))
 	lit := &ast.IntegerLiteral{Token: p.currToken}
 
 	value, err := strconv.ParseInt(p.currToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.currToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.currToken.Pos, "could not parse %q as integer", p.currToken.Literal)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.currToken}
+
+	value, err := strconv.ParseFloat(p.currToken.Literal, 64)
+	if err != nil {
+		p.addError(p.currToken.Pos, "could not parse %q as float", p.currToken.Literal)
 		return nil
 	}
 
@@ -269,6 +588,42 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
+}
+
+// f"Hello ${name}!"
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.currToken}
+	str.Parts = p.appendStringPart(str.Parts)
+
+	for p.nextTokenIs(token.INTERP_START) {
+		p.advance() // ${
+		p.advance()
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if !p.advanceIfNextTokenIs(token.INTERP_END) {
+			return nil
+		}
+		// the lexer always resumes the string after a }
+		if !p.advanceIfNextTokenIs(token.STRING_PART) {
+			return nil
+		}
+		str.Parts = p.appendStringPart(str.Parts)
+	}
+
+	return str
+}
+
+// appendStringPart appends the text of the curr token.STRING_PART to parts,
+// unless it is empty.
+func (p *Parser) appendStringPart(parts []ast.Expression) []ast.Expression {
+	if p.currToken.Literal == "" {
+		return parts
+	}
+	return append(parts, &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal})
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	// defer untrace(trace("parsePrefixExpression"))
 	expression := &ast.PrefixExpression{
@@ -298,6 +653,102 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parseRightInfixExpression parses a right-associative operator by parsing
+// its right side one precedence level lower, so 2 ** 3 ** 2 is 2 ** (3 ** 2).
+func (p *Parser) parseRightInfixExpression(left ast.Expression) ast.Expression {
+	expression := &ast.InfixExpression{
+		Token:    p.currToken,
+		Operator: p.currToken.Literal,
+		Left:     left,
+	}
+
+	precedence := p.currPrecedence()
+	p.advance()
+	expression.Right = p.parseExpression(precedence - 1)
+
+	return expression
+}
+
+// ++x or --x
+func (p *Parser) parsePrefixIncrement() ast.Expression {
+	tok := p.currToken
+	p.advance()
+	return p.desugarIncrement(tok, p.parseExpression(PREFIX))
+}
+
+// x++ or x--
+func (p *Parser) parsePostfixIncrement(operand ast.Expression) ast.Expression {
+	return p.desugarIncrement(p.currToken, operand)
+}
+
+// desugarIncrement rewrites ++x (and x++) as x = x + 1, and --x (and x--) as
+// x = x - 1. Both forms therefore evaluate to the updated value. ++arr[i]
+// becomes arr[i] = arr[i] + 1 the same way, so arr and i are evaluated twice.
+func (p *Parser) desugarIncrement(tok token.Token, operand ast.Expression) ast.Expression {
+	operator := tok.Literal[:1] // + or -
+	value := &ast.InfixExpression{
+		Token:    token.Token{Type: token.TokenType(operator), Literal: operator, Pos: tok.Pos},
+		Left:     operand,
+		Operator: operator,
+		Right: &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: "1", Pos: tok.Pos},
+			Value: 1,
+		},
+	}
+
+	switch operand := operand.(type) {
+	case *ast.Identifier:
+		return &ast.AssignStatement{Token: tok, Name: operand, Value: value}
+	case *ast.IndexExpression:
+		return &ast.IndexAssignStatement{Token: tok, Target: operand, Value: value}
+	default:
+		p.addError(tok.Pos, "cannot apply %s to %s", tok.Literal, operand)
+		return nil
+	}
+}
+
+// parsePipeExpression rewrites x |> f as the call f(x). Pipes associate to the
+// left, so x |> f |> g is g(f(x)).
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	expression := &ast.CallExpression{
+		Token:     p.currToken,
+		Arguments: []ast.Expression{left},
+	}
+
+	precedence := p.currPrecedence()
+	p.advance()
+	expression.Function = p.parseExpression(precedence)
+
+	return expression
+}
+
+// x > 0 ? x : -x
+//
+// Both branches are parsed at LOWEST, so ternaries nest to the right:
+// a ? b : c ? d : e is a ? b : (c ? d : e).
+func (p *Parser) parseTernaryExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.TernaryExpression{Token: p.currToken, Condition: condition}
+
+	p.advance()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.advanceIfNextTokenIs(token.COLON) {
+		return nil
+	}
+
+	p.advance()
+	expression.Alternative = p.parseExpression(LOWEST)
+
+	return expression
+}
+
+// parseReservedInfix reports operators that are lexed but do not mean
+// anything yet, such as ->.
+func (p *Parser) parseReservedInfix(left ast.Expression) ast.Expression {
+	p.addError(p.currToken.Pos, "'%s' is reserved for future use", p.currToken.Literal)
+	return nil
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.currToken}
 
@@ -321,6 +772,23 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.nextTokenIs(token.ELSE) {
 		p.advance()
 
+		// else if (...) { ... } is an else block holding just the nested if
+		if p.nextTokenIs(token.IF) {
+			p.advance()
+			tok := p.currToken
+
+			nested := p.parseIfExpression()
+			if nested == nil {
+				return nil
+			}
+
+			expression.Alternative = &ast.BlockStatement{
+				Token:      tok,
+				Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: nested}},
+			}
+			return expression
+		}
+
 		if !p.advanceIfNextTokenIs(token.LBRACE) {
 			return nil
 		}
@@ -344,6 +812,10 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		p.advance()
 	}
 
+	if p.currTokenIs(token.RBRACE) {
+		block.Rbrace = p.currToken.Pos
+	}
+
 	return block
 }
 
@@ -367,6 +839,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
+	seen := make(map[string]bool)
 
 	// ()
 	if p.nextTokenIs(token.RPAREN) {
@@ -377,12 +850,18 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	p.advance()
 	ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 	identifiers = append(identifiers, ident)
+	seen[ident.Value] = true
 
 	for p.nextTokenIs(token.COMMA) {
 		p.advance() // ,
 		p.advance() // ident
 		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if seen[ident.Value] {
+			p.duplicateParameterError(ident.Value)
+			continue
+		}
 		identifiers = append(identifiers, ident)
+		seen[ident.Value] = true
 	}
 
 	if !p.advanceIfNextTokenIs(token.RPAREN) {
@@ -396,38 +875,91 @@ func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 	return &ast.CallExpression{
 		Token:     p.currToken,
 		Function:  fn,
-		Arguments: p.parseCallArguments(),
+		Arguments: p.parseExpressionList(token.RPAREN),
+	}
+}
+
+// arr[0]
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
+
+	p.advance()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.advanceIfNextTokenIs(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+// [1, 2 * 3, "a"]
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	return &ast.ArrayLiteral{
+		Token:    p.currToken,
+		Elements: p.parseExpressionList(token.RBRACKET),
+	}
+}
+
+// {"one": 1, "two": 1 + 1}
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currToken, Pairs: []ast.HashPair{}}
+
+	for !p.nextTokenIs(token.RBRACE) {
+		p.advance()
+		key := p.parseExpression(LOWEST)
+
+		if !p.advanceIfNextTokenIs(token.COLON) {
+			return nil
+		}
+
+		p.advance()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.nextTokenIs(token.RBRACE) && !p.advanceIfNextTokenIs(token.COMMA) {
+			return nil
+		}
 	}
+
+	if !p.advanceIfNextTokenIs(token.RBRACE) {
+		return nil
+	}
+
+	return hash
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+// parseExpressionList parses comma separated expressions up to the end token,
+// starting from the opening token, e.g. the call arguments in f(x, y).
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
 
 	// ()
 	// ^
-	if p.nextTokenIs(token.RPAREN) {
+	if p.nextTokenIs(end) {
 		p.advance()
-		return args
+		return list
 	}
 
 	// ( x, ... )
 	//   ^
 	p.advance()
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	// ( x, ... )
 	//   ^
 	for p.nextTokenIs(token.COMMA) {
 		p.advance()
 		p.advance()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.advanceIfNextTokenIs(token.RPAREN) {
+	if !p.advanceIfNextTokenIs(end) {
 		return nil
 	}
 
-	return args
+	return list
 }
 
 // Helpers
@@ -442,8 +974,7 @@ func (p *Parser) nextTokenIs(t token.TokenType) bool {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be '%s', got '%s' parsing: '%s ...'", t, p.peekToken.Type, p.Progress())
-	p.errors = append(p.errors, msg)
+	p.addError(p.currToken.Pos, "expected next token to be '%s', got '%s' parsing: '%s ...'", t, p.peekToken.Type, p.Progress())
 }
 
 func (p *Parser) advanceIfNextTokenIs(t token.TokenType) bool {
@@ -457,8 +988,11 @@ func (p *Parser) advanceIfNextTokenIs(t token.TokenType) bool {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.currToken.Pos, "no prefix parse function for %s found", t)
+}
+
+func (p *Parser) duplicateParameterError(name string) {
+	p.addError(p.currToken.Pos, "duplicate parameter name '%s'", name)
 }
 
 func (p *Parser) peekPrecedence() int {
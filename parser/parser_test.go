@@ -4,6 +4,8 @@ import (
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/test"
+	"monkey/token"
+	"strings"
 	"testing"
 )
 
@@ -33,6 +35,120 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestAssertBoolLiteralHelper(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"true;", true},
+		{"false;", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		assertBoolLiteral(t, stmt.Expression, tt.want)
+		assertLiteralExpression(t, stmt.Expression, tt.want)
+	}
+}
+
+func TestProgramHasErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"", false},
+		{"let x = 5;", false},
+		{"let = 5;", true},
+	}
+
+	for _, tt := range tests {
+		program := New(lexer.New(tt.input)).ParseProgram()
+		assertProgramNotNil(t, program)
+		test.AssertEqual(t, program.HasErrors, tt.want)
+	}
+}
+
+func TestParserErrorsIncludePosition(t *testing.T) {
+	input := "let x = 5;\nlet = 10;"
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+	test.AssertEqual(t, errors[0].Error(), "2:1: expected next token to be 'NAME', got '=' parsing: 'let ...'")
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	input := `
+let = 1;
+let y 2;
+let 3 = z;
+let ok = 4;
+`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	errors := p.Errors()
+	test.AssertEqual(t, len(errors), 3)
+	test.AssertTrue(t, strings.HasPrefix(errors[0].Error(), "2:1: expected next token to be 'NAME', got '='"))
+	test.AssertTrue(t, strings.HasPrefix(errors[1].Error(), "3:5: expected next token to be '=', got 'INT'"))
+	test.AssertTrue(t, strings.HasPrefix(errors[2].Error(), "4:1: expected next token to be 'NAME', got 'INT'"))
+
+	// the statement after the errors is still parsed
+	assertProgramStatements(t, program, 1)
+	assertLetStatement(t, program.Statements[0], "ok")
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	input := `let x = 5;
+let = 10;
+let y = 1;
+let 2;
+return -> x;`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	wantLines := []int{2, 4, 5}
+	errors := p.Errors()
+	if len(errors) != len(wantLines) {
+		t.Fatalf("expected %d errors, got=%d %q", len(wantLines), len(errors), p.ParseErrors())
+	}
+	for i, line := range wantLines {
+		test.AssertEqual(t, errors[i].Pos.Line, line)
+	}
+
+	test.AssertEqual(t, errors[1].Message, "expected next token to be 'NAME', got 'INT' parsing: 'let ...'")
+	test.AssertEqual(t, p.ParseErrors()[1].Error(), "4:1: "+errors[1].Message)
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	input := "let x = 5; /* never closed"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertProgramStatements(t, program, 1)
+	test.AssertTrue(t, program.HasErrors)
+	test.AssertEqual(t, len(p.Errors()), 1)
+	test.AssertEqual(t, p.Errors()[0].Error(), "unterminated block comment")
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 		return 5;
@@ -54,12 +170,57 @@ func TestReturnStatements(t *testing.T) {
 			t.Errorf("statement is not *ast.ReturnStatement, got=%T", stmt)
 			continue
 		}
-		if lit := returnStmt.TokenLiteral(); lit != "return" {
-			t.Errorf("returnStmt.TokenLiteral not 'return', got %q", lit)
+		if lit := returnStmt.FirstTokenLiteral(); lit != "return" {
+			t.Errorf("returnStmt.FirstTokenLiteral not 'return', got %q", lit)
 		}
 	}
 }
 
+func TestImportStatementParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+	}{
+		{`import "stdlib/math"`, "stdlib/math"},
+		{`import "strings";`, "strings"},
+		{`fn() { import "strings"; }`, "strings"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramStatements(t, program, 1)
+
+		var imp ast.Node
+		ast.Inspect(program, func(node ast.Node) bool {
+			if _, ok := node.(*ast.ImportStatement); ok {
+				imp = node
+			}
+			return imp == nil
+		})
+		stmt, ok := imp.(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("no ast.ImportStatement in %q", program)
+		}
+		test.AssertEqual(t, stmt.FirstTokenLiteral(), "import")
+		test.AssertEqual(t, stmt.Path.Value, tt.path)
+	}
+}
+
+func TestImportStatementErrors(t *testing.T) {
+	l := lexer.New("import strings;")
+	p := New(l)
+	program := p.ParseProgram()
+
+	errors := p.Errors()
+	test.AssertEqual(t, len(errors), 1)
+	test.AssertTrue(t, strings.HasPrefix(errors[0].Message, "expected next token to be 'STRING', got 'NAME'"))
+	assertProgramStatements(t, program, 0)
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "foobar;"
 	l := lexer.New(input)
@@ -81,8 +242,8 @@ func TestIdentifierExpression(t *testing.T) {
 		t.Errorf("ident.Value is not %s, got=%s", "foobar", got)
 	}
 
-	if got := ident.TokenLiteral(); got != "foobar" {
-		t.Errorf("ident.TokenLiteral is not %s, got=%s", "foobar", got)
+	if got := ident.FirstTokenLiteral(); got != "foobar" {
+		t.Errorf("ident.FirstTokenLiteral is not %s, got=%s", "foobar", got)
 	}
 }
 
@@ -105,7 +266,137 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 
 	test.AssertEqual(t, literal.Value, 5)
-	test.AssertEqual(t, literal.TokenLiteral(), "5")
+	test.AssertEqual(t, literal.FirstTokenLiteral(), "5")
+}
+
+func TestHexLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"0x0", 0},
+		{"0xFF", 255},
+		{"0xff", 255},
+		{"0XDEAD", 57005},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expression is not &ast.IntegerLiteral, got=%T", stmt.Expression)
+		}
+		test.AssertEqual(t, literal.Value, tt.want)
+		test.AssertEqual(t, literal.FirstTokenLiteral(), tt.input)
+	}
+}
+
+func TestOctalBinaryLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"0o7", 7},
+		{"0o17", 15},
+		{"0O17", 15},
+		{"0b101", 5},
+		{"0b11111111", 255},
+		{"0B1", 1},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expression is not &ast.IntegerLiteral, got=%T", stmt.Expression)
+		}
+		test.AssertEqual(t, literal.Value, tt.want)
+		test.AssertEqual(t, literal.FirstTokenLiteral(), tt.input)
+	}
+}
+
+func TestInvalidBinaryLiteral(t *testing.T) {
+	l := lexer.New("0b2")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+	test.AssertEqual(t, errors[0].Error(), `1:1: could not parse "0b" as integer`)
+}
+
+func TestFloatLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		literal string
+	}{
+		{"0.5", 0.5, "0.5"},
+		{"3.14", 3.14, "3.14"},
+		{"100.0", 100.0, "100.0"},
+		{"3.", 3.0, "3."},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		assertLiteralExpression(t, stmt.Expression, tt.want)
+		test.AssertEqual(t, stmt.Expression.FirstTokenLiteral(), tt.literal)
+	}
+}
+
+func TestStringLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"hello world"`, "hello world"},
+		{`""`, ""},
+		{`"Hello, world! (really?) 1 + 2;"`, "Hello, world! (really?) 1 + 2;"},
+		{`"say \"hi\"\n"`, "say \"hi\"\n"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("expression is not ast.StringLiteral, got=%T", stmt.Expression)
+		}
+		test.AssertEqual(t, literal.Value, tt.want)
+	}
 }
 
 func TestBoolLiteralExpression(t *testing.T) {
@@ -125,7 +416,7 @@ func TestBoolLiteralExpression(t *testing.T) {
 		t.Fatalf("expression is not &ast.BoolLiteral, got=%T", stmt1.Expression)
 	}
 	test.AssertEqual(t, lit1.Value, true)
-	test.AssertEqual(t, lit1.TokenLiteral(), "true")
+	test.AssertEqual(t, lit1.FirstTokenLiteral(), "true")
 
 	stmt2 := assertExpressionStatement(t, program.Statements[1])
 	lit2, ok := stmt2.Expression.(*ast.BoolLiteral)
@@ -133,7 +424,65 @@ func TestBoolLiteralExpression(t *testing.T) {
 		t.Fatalf("expression is not &ast.BoolLiteral, got=%T", stmt1.Expression)
 	}
 	test.AssertEqual(t, lit2.Value, false)
-	test.AssertEqual(t, lit2.TokenLiteral(), "false")
+	test.AssertEqual(t, lit2.FirstTokenLiteral(), "false")
+}
+
+func TestStringInterpolationParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		parts []string
+		want  string
+	}{
+		{`f"Hello ${name}!"`, []string{"Hello ", "name", "!"}, `f"Hello ${name}!"`},
+		{`f"${1 + 2} items"`, []string{"(1 + 2)", " items"}, `f"${(1 + 2)} items"`},
+		{`f"${fn(x){x}(5)} widgets"`, []string{"fn(x) {\n    x\n}(5)", " widgets"}, "f\"${fn(x) {\n    x\n}(5)} widgets\""},
+		{`f"plain"`, []string{"plain"}, `f"plain"`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramStatements(t, program, 1)
+		stmt := assertExpressionStatement(t, program.Statements[0])
+
+		str, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Fatalf("expression is not ast.InterpolatedString, got=%T", stmt.Expression)
+		}
+		test.AssertEqual(t, len(str.Parts), len(tt.parts))
+		for i, part := range tt.parts {
+			assertNodeString(t, str.Parts[i], part)
+		}
+		test.AssertEqual(t, str.String(), tt.want)
+	}
+}
+
+func TestNullLiteralParsing(t *testing.T) {
+	input := "null; let x = null;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertParserNoErrors(t, p)
+	assertProgramNotNil(t, program)
+	assertProgramStatements(t, program, 2)
+
+	stmt := assertExpressionStatement(t, program.Statements[0])
+	if _, ok := stmt.Expression.(*ast.NullLiteral); !ok {
+		t.Fatalf("expression is not ast.NullLiteral, got=%T", stmt.Expression)
+	}
+
+	assertLetStatement(t, program.Statements[1], "x")
+	value := program.Statements[1].(*ast.LetStatement).Value
+	if _, ok := value.(*ast.NullLiteral); !ok {
+		t.Fatalf("let value is not ast.NullLiteral, got=%T", value)
+	}
+
+	test.AssertEqual(t, program.String(), "nulllet x = null;")
 }
 
 func TestParsingPrefixExpressions(t *testing.T) {
@@ -198,8 +547,224 @@ func TestParsingInfixExpressions(t *testing.T) {
 			t.Fatalf("exp is not ast.InfixExpression, got %T", stmt.Expression)
 		}
 
-		assertInfixExpression(t, exp, tt.leftValue, tt.operator, tt.rightValue)
+		assertInfixExpression(t, exp, tt.leftValue, tt.operator, tt.rightValue)
+	}
+}
+
+func TestGTELTEParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 >= 3", "(5 >= 3)"},
+		{"x <= 10", "(x <= 10)"},
+		{"a >= b == c <= d", "((a >= b) == (c <= d))"},
+		{"!(x >= y)", "(!(x >= y))"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestLogicalOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"true && false || true", "((true && false) || true)"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"x > 0 || !y", "((x > 0) || (!y))"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestModuloParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"10 % 3", "(10 % 3)"},
+		{"a % b + c", "((a % b) + c)"},
+		{"a * b % c", "((a * b) % c)"},
+		{"-(a % b)", "(-(a % b))"},
+		{"foo(10 % 3)", "foo((10 % 3))"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestExponentiationPrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2 ** 3", "(2 ** 3)"},
+		{"2 ** 3 ** 2", "(2 ** (3 ** 2))"},
+		{"4 ** 0.5 * 2", "((4 ** 0.5) * 2)"},
+		{"2 * 3 ** 2", "(2 * (3 ** 2))"},
+		{"-2 ** 2", "((-2) ** 2)"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+}
+
+func TestIncrementDecrementParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"++i", "i = (i + 1)"},
+		{"i++", "i = (i + 1)"},
+		{"--j", "j = (j - 1)"},
+		{"j--", "j = (j - 1)"},
+		{"foo(++i, j--)", "foo(i = (i + 1), j = (j - 1))"},
+		{"++arr[0]", "(arr[0]) = ((arr[0]) + 1)"},
+		{"arr[i]--", "(arr[i]) = ((arr[i]) - 1)"},
+		{"puts(++h[\"a\"])", "puts((h[a]) = ((h[a]) + 1))"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	l := lexer.New("++5")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	test.AssertEqual(t, len(errors), 1)
+	test.AssertEqual(t, errors[0].Error(), "1:1: cannot apply ++ to 5")
+}
+
+func TestPipeOperatorParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5 |> double", "double(5)"},
+		{"5 |> double |> toString", "toString(double(5))"},
+		{"arr |> filter(isEven) |> first", "first(filter(isEven)(arr))"},
+		{"a + b |> f", "f((a + b))"},
+		{"x |> f || g", "(f || g)(x)"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	program := New(lexer.New("5 |> double |> toString")).ParseProgram()
+	stmt := assertExpressionStatement(t, program.Statements[0])
+
+	outer, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.CallExpression, got=%T", stmt.Expression)
+	}
+	assertIdentifier(t, outer.Function, "toString")
+	test.AssertEqual(t, len(outer.Arguments), 1)
+
+	inner, ok := outer.Arguments[0].(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("argument is not ast.CallExpression, got=%T", outer.Arguments[0])
+	}
+	assertIdentifier(t, inner.Function, "double")
+	test.AssertEqual(t, len(inner.Arguments), 1)
+	assertLiteralExpression(t, inner.Arguments[0], 5)
+}
+
+func TestTernaryParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"x > 0 ? x : -x", "((x > 0) ? x : (-x))"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a || b ? 1 + 2 : 3", "((a || b) ? (1 + 2) : 3)"},
+		{"fn(x) { x > 0 ? x : 0 }", "fn(x) {\n    ((x > 0) ? x : 0)\n}"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	program := New(lexer.New("x < y ? x : y")).ParseProgram()
+	stmt := assertExpressionStatement(t, program.Statements[0])
+
+	exp, ok := stmt.Expression.(*ast.TernaryExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.TernaryExpression, got=%T", stmt.Expression)
 	}
+	assertInfixExpression(t, exp.Condition, "x", "<", "y")
+	assertIdentifier(t, exp.Consequence, "x")
+	assertIdentifier(t, exp.Alternative, "y")
+}
+
+func TestReservedArrowOperator(t *testing.T) {
+	l := lexer.New("5 -> 10")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	test.AssertEqual(t, len(errors), 1)
+	test.AssertEqual(t, errors[0].Error(), "1:3: '->' is reserved for future use")
 }
 
 func TestOperatorPrecedenceParsing(t *testing.T) {
@@ -303,6 +868,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
 	}
 
 	for i, tt := range tests {
@@ -388,6 +961,44 @@ func TestIfElseExpression(t *testing.T) {
 	assertIdentifier(t, alternative.Expression, "y")
 }
 
+func TestElseIfChainParsing(t *testing.T) {
+	input := `if (a) { 1 } else if (b) { 2 } else if (c) { 3 } else { 4 }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertParserNoErrors(t, p)
+	assertProgramStatements(t, program, 1)
+	stmt := assertExpressionStatement(t, program.Statements[0])
+
+	exp := stmt.Expression
+	var last *ast.BlockStatement
+	for i, cond := range []string{"a", "b", "c"} {
+		ifExp, ok := exp.(*ast.IfExpression)
+		if !ok {
+			t.Fatalf("if #%d is not ast.IfExpression, got=%T", i, exp)
+		}
+		assertIdentifier(t, ifExp.Condition, cond)
+
+		test.AssertEqual(t, len(ifExp.Consequence.Statements), 1)
+		consequence := assertExpressionStatement(t, ifExp.Consequence.Statements[0])
+		assertLiteralExpression(t, consequence.Expression, i+1)
+
+		if ifExp.Alternative == nil {
+			t.Fatalf("if #%d has no alternative", i)
+		}
+		test.AssertEqual(t, len(ifExp.Alternative.Statements), 1)
+		alternative := assertExpressionStatement(t, ifExp.Alternative.Statements[0])
+		exp = alternative.Expression
+		last = ifExp.Alternative
+	}
+
+	// the final else is a plain block
+	test.AssertEqual(t, last.Token.Type, token.LBRACE)
+	assertLiteralExpression(t, exp, 4)
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
@@ -418,14 +1029,15 @@ func TestFunctionLiteralParsing(t *testing.T) {
 	assertInfixExpression(t, body.Expression, "x", "+", "y")
 }
 
-func TetFunctionParameterParsing(t *testing.T) {
+func TestFunctionParameterParsing(t *testing.T) {
 	tests := []struct {
 		input          string
 		expectedParams []string
 	}{
-		{input: "fn() {};", expectedParams: []string{}},
-		{input: "fn(x) {};", expectedParams: []string{"x"}},
-		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+		{input: "fn() {}", expectedParams: []string{}},
+		{input: "fn(x) {}", expectedParams: []string{"x"}},
+		{input: "fn(x, y, z) {}", expectedParams: []string{"x", "y", "z"}},
+		{input: "fn(a, b, c, d, e) {}", expectedParams: []string{"a", "b", "c", "d", "e"}},
 	}
 
 	for _, tt := range tests {
@@ -434,16 +1046,33 @@ func TetFunctionParameterParsing(t *testing.T) {
 		program := p.ParseProgram()
 		assertParserNoErrors(t, p)
 		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
 		exp := assertExpressionStatement(t, program.Statements[0])
-		fn := exp.Expression.(*ast.FunctionLiteral)
+		fn, ok := exp.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("exp.Expression is not ast.FunctionLiteral, got=%T", exp.Expression)
+		}
 		test.AssertEqual(t, len(fn.Parameters), len(tt.expectedParams))
 
 		for i, ident := range tt.expectedParams {
-			assertLiteralExpression(t, fn.Parameters[i], ident)
+			assertIdentifier(t, fn.Parameters[i], ident)
 		}
 	}
 }
 
+func TestFunctionParameterDuplicates(t *testing.T) {
+	input := "fn(a, b, a) {}"
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	test.AssertEqual(t, len(errors), 1)
+	test.AssertEqual(t, errors[0].Error(), "1:10: duplicate parameter name 'a'")
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -470,5 +1099,424 @@ func TestCallExpressionParsing(t *testing.T) {
 }
 
 func TestCallExpressionArgumentParsing(t *testing.T) {
-	t.SkipNow()
+	tests := []struct {
+		input      string
+		callee     string
+		assertArgs func(t *testing.T, args []ast.Expression)
+	}{
+		{
+			input:  "foo()",
+			callee: "foo",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 0)
+			},
+		},
+		{
+			input:  "foo(x)",
+			callee: "foo",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 1)
+				assertLiteralExpression(t, args[0], "x")
+			},
+		},
+		{
+			input:  "foo(1, 2 * 3, 4 + 5)",
+			callee: "foo",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 3)
+				assertLiteralExpression(t, args[0], 1)
+				assertInfixExpression(t, args[1], 2, "*", 3)
+				assertInfixExpression(t, args[2], 4, "+", 5)
+			},
+		},
+		{
+			input:  "foo(fn(x) { x })",
+			callee: "foo",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 1)
+				fn, ok := args[0].(*ast.FunctionLiteral)
+				if !ok {
+					t.Fatalf("args[0] is not ast.FunctionLiteral, got=%T", args[0])
+				}
+				test.AssertEqual(t, len(fn.Parameters), 1)
+				assertIdentifier(t, fn.Parameters[0], "x")
+				test.AssertEqual(t, len(fn.Body.Statements), 1)
+				body := assertExpressionStatement(t, fn.Body.Statements[0])
+				assertLiteralExpression(t, body.Expression, "x")
+			},
+		},
+		{
+			input:  "outer(inner(1, 2), 3 + inner(4, 5))",
+			callee: "outer",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 2)
+
+				inner, ok := args[0].(*ast.CallExpression)
+				if !ok {
+					t.Fatalf("args[0] is not ast.CallExpression, got=%T", args[0])
+				}
+				assertIdentifier(t, inner.Function, "inner")
+				test.AssertEqual(t, len(inner.Arguments), 2)
+				assertLiteralExpression(t, inner.Arguments[0], 1)
+				assertLiteralExpression(t, inner.Arguments[1], 2)
+
+				sum, ok := args[1].(*ast.InfixExpression)
+				if !ok {
+					t.Fatalf("args[1] is not ast.InfixExpression, got=%T", args[1])
+				}
+				assertLiteralExpression(t, sum.Left, 3)
+				test.AssertEqual(t, sum.Operator, "+")
+				test.AssertEqual(t, sum.Right.String(), "inner(4, 5)")
+			},
+		},
+		{
+			input:  "fn(x) { x }(5)",
+			callee: "",
+			assertArgs: func(t *testing.T, args []ast.Expression) {
+				test.AssertEqual(t, len(args), 1)
+				assertLiteralExpression(t, args[0], 5)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramStatements(t, program, 1)
+		stmt := assertExpressionStatement(t, program.Statements[0])
+
+		exp, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("expression is not call expression, got=%T", stmt.Expression)
+		}
+
+		// an empty callee stands for an inline function literal
+		if tt.callee == "" {
+			if _, ok := exp.Function.(*ast.FunctionLiteral); !ok {
+				t.Fatalf("exp.Function is not ast.FunctionLiteral, got=%T", exp.Function)
+			}
+		} else {
+			assertIdentifier(t, exp.Function, tt.callee)
+		}
+
+		tt.assertArgs(t, exp.Arguments)
+	}
+}
+
+func TestForStatementParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		init      string
+		condition string
+		post      string
+		want      string
+	}{
+		{
+			"for (let i = 0; i < 10; i += 1) { puts(i); }",
+			"let i = 0;", "(i < 10)", "i += 1;",
+			"for (let i = 0; (i < 10); i += 1) {\n    puts(i)\n}",
+		},
+		{
+			"for (let i = 0; i < 10; i = i + 1) {}",
+			"let i = 0;", "(i < 10)", "i = (i + 1)",
+			"for (let i = 0; (i < 10); i = (i + 1)) {}",
+		},
+		{
+			"for (;; x += 1) {}",
+			"", "", "x += 1;",
+			"for (;; x += 1) {}",
+		},
+		{
+			"for (;;) {}",
+			"", "", "",
+			"for (;;) {}",
+		},
+		{
+			"for (i; i < n;) { next(); }",
+			"i", "(i < n)", "",
+			"for (i; (i < n);) {\n    next()\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("statement is not ast.ForStatement, got=%T", program.Statements[0])
+		}
+
+		assertNodeString(t, stmt.Init, tt.init)
+		assertNodeString(t, stmt.Condition, tt.condition)
+		assertNodeString(t, stmt.Post, tt.post)
+		test.AssertEqual(t, stmt.String(), tt.want)
+	}
+}
+
+func TestCompoundAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		operator string
+		value    interface{}
+	}{
+		{"x += 5;", "x", "+=", 5},
+		{"x -= y", "x", "-=", "y"},
+		{"total *= 2;", "total", "*=", 2},
+		{"ratio /= 10", "ratio", "/=", 10},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.CompoundAssignStatement)
+		if !ok {
+			t.Fatalf("statement is not ast.CompoundAssignStatement, got=%T", program.Statements[0])
+		}
+
+		assertIdentifier(t, stmt.Name, tt.name)
+		test.AssertEqual(t, stmt.Operator, tt.operator)
+		assertLiteralExpression(t, stmt.Value, tt.value)
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := "let a = 1;\nlet b =\n    a + 2;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertParserNoErrors(t, p)
+	assertProgramStatements(t, program, 2)
+
+	stmt := program.Statements[1].(*ast.LetStatement)
+	test.AssertEqual(t, stmt.Token.Pos, token.Position{Line: 2, Col: 1, Offset: 11})
+	test.AssertEqual(t, stmt.Name.Token.Pos, token.Position{Line: 2, Col: 5, Offset: 15})
+
+	value := stmt.Value.(*ast.InfixExpression)
+	test.AssertEqual(t, value.Token.Pos, token.Position{Line: 3, Col: 7, Offset: 25})
+	test.AssertEqual(t, value.Left.(*ast.Identifier).Token.Pos, token.Position{Line: 3, Col: 5, Offset: 23})
+}
+
+func TestArrayLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		len   int
+	}{
+		{"[]", "[]", 0},
+		{"[1]", "[1]", 1},
+		{`[1, true, "a"]`, "[1, true, a]", 3},
+		{"[1 + 2, 3 * 4]", "[(1 + 2), (3 * 4)]", 2},
+		{"[[1, 2], [3, 4]]", "[[1, 2], [3, 4]]", 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("expression is not ast.ArrayLiteral, got=%T", stmt.Expression)
+		}
+
+		test.AssertEqual(t, len(array.Elements), tt.len)
+		test.AssertEqual(t, array.String(), tt.want)
+	}
+
+	program := New(lexer.New(`[1 + 2, true, "a"]`)).ParseProgram()
+	array := assertExpressionStatement(t, program.Statements[0]).Expression.(*ast.ArrayLiteral)
+
+	assertInfixExpression(t, array.Elements[0], 1, "+", 2)
+	assertLiteralExpression(t, array.Elements[1], true)
+	test.AssertEqual(t, array.Elements[2].(*ast.StringLiteral).Value, "a")
+}
+
+func TestHashLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		len   int
+	}{
+		{"{}", "{}", 0},
+		{`{"one": 1, "two": 2}`, "{one: 1, two: 2}", 2},
+		{`{1: "one", 2: "two"}`, "{1: one, 2: two}", 2},
+		{`{true: 1 + 2,}`, "{true: (1 + 2)}", 1},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt := assertExpressionStatement(t, program.Statements[0])
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Fatalf("expression is not ast.HashLiteral, got=%T", stmt.Expression)
+		}
+
+		test.AssertEqual(t, len(hash.Pairs), tt.len)
+		test.AssertEqual(t, hash.String(), tt.want)
+	}
+
+	program := New(lexer.New(`{"one": 0 + 1, 2: two}`)).ParseProgram()
+	hash := assertExpressionStatement(t, program.Statements[0]).Expression.(*ast.HashLiteral)
+
+	test.AssertEqual(t, hash.Pairs[0].Key.(*ast.StringLiteral).Value, "one")
+	assertInfixExpression(t, hash.Pairs[0].Value, 0, "+", 1)
+	assertLiteralExpression(t, hash.Pairs[1].Key, 2)
+	assertLiteralExpression(t, hash.Pairs[1].Value, "two")
+}
+
+func TestIndexExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"arr[0]", "(arr[0])"},
+		{`hash["key"]`, "(hash[key])"},
+		{"getArr()[0]", "(getArr()[0])"},
+		{"arr[1 + 2]", "(arr[(1 + 2)])"},
+		{"matrix[0][1]", "((matrix[0])[1])"},
+	}
+
+	for i, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		assertParserNoErrors(t, p)
+
+		if got := program.String(); got != tt.want {
+			t.Errorf("#%d want=%q, got=%q", i, tt.want, got)
+		}
+	}
+
+	program := New(lexer.New("arr[1 + 1]")).ParseProgram()
+	stmt := assertExpressionStatement(t, program.Statements[0])
+
+	exp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.IndexExpression, got=%T", stmt.Expression)
+	}
+	assertIdentifier(t, exp.Left, "arr")
+	assertInfixExpression(t, exp.Index, 1, "+", 1)
+}
+
+func TestWhileStatementParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		condition string
+		body      int
+	}{
+		{"while (x > 0) { x -= 1; }", "(x > 0)", 1},
+		{"while (x > 0 && !done(x)) { x -= 1; log(x); }", "((x > 0) && (!done(x)))", 2},
+		{"while (true) {}", "true", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		assertParserNoErrors(t, p)
+		assertProgramNotNil(t, program)
+		assertProgramStatements(t, program, 1)
+
+		stmt, ok := program.Statements[0].(*ast.WhileStatement)
+		if !ok {
+			t.Fatalf("statement is not ast.WhileStatement, got=%T", program.Statements[0])
+		}
+
+		test.AssertEqual(t, stmt.Condition.String(), tt.condition)
+		test.AssertEqual(t, len(stmt.Body.Statements), tt.body)
+	}
+}
+
+func TestBreakContinueParsing(t *testing.T) {
+	input := "while (true) { if (done) { break; } continue }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertParserNoErrors(t, p)
+	assertProgramStatements(t, program, 1)
+
+	loop, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.WhileStatement, got=%T", program.Statements[0])
+	}
+	test.AssertEqual(t, len(loop.Body.Statements), 2)
+
+	ifStmt := assertExpressionStatement(t, loop.Body.Statements[0])
+	ifExp, ok := ifStmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.IfExpression, got=%T", ifStmt.Expression)
+	}
+	test.AssertEqual(t, len(ifExp.Consequence.Statements), 1)
+	if _, ok := ifExp.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("statement is not ast.BreakStatement, got=%T", ifExp.Consequence.Statements[0])
+	}
+
+	if _, ok := loop.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("statement is not ast.ContinueStatement, got=%T", loop.Body.Statements[1])
+	}
+}
+
+func TestAssignStatementParsing(t *testing.T) {
+	l := lexer.New("x = 10; arr[0] = 5; let y = 5; x == 10;")
+	p := New(l)
+	program := p.ParseProgram()
+
+	assertParserNoErrors(t, p)
+	assertProgramNotNil(t, program)
+	assertProgramStatements(t, program, 4)
+
+	assign, ok := program.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.AssignStatement, got=%T", program.Statements[0])
+	}
+	assertIdentifier(t, assign.Name, "x")
+	assertLiteralExpression(t, assign.Value, 10)
+
+	indexAssign, ok := program.Statements[1].(*ast.IndexAssignStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.IndexAssignStatement, got=%T", program.Statements[1])
+	}
+	assertIdentifier(t, indexAssign.Target.Left, "arr")
+	assertLiteralExpression(t, indexAssign.Target.Index, 0)
+	assertLiteralExpression(t, indexAssign.Value, 5)
+
+	assertLetStatement(t, program.Statements[2], "y")
+
+	stmt := assertExpressionStatement(t, program.Statements[3])
+	assertInfixExpression(t, stmt.Expression, "x", "==", 10)
+
+	test.AssertEqual(t, program.String(), "x = 10(arr[0]) = 5let y = 5;(x == 10)")
 }
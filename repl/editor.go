@@ -0,0 +1,166 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// lineReader reads input a line at a time, writing prompt first. It returns
+// io.EOF once the input ends.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scannerReader reads lines from plain input, e.g. a pipe or a file.
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// lineEditor reads lines from a terminal in raw mode, where it echoes the
+// input itself. The up and down arrows step through the history.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *History
+
+	// rawMode switches the terminal to raw mode, if set, returning a func
+	// that switches it back
+	rawMode func() (restore func(), err error)
+
+	// complete returns the completions of an identifier prefix for Tab, if
+	// set
+	complete func(prefix string) []string
+}
+
+func (le *lineEditor) ReadLine(prompt string) (string, error) {
+	if le.rawMode != nil {
+		restore, err := le.rawMode()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	fmt.Fprint(le.out, prompt)
+	var line []rune
+	redraw := func() {
+		fmt.Fprintf(le.out, "\r\x1b[K%s%s", prompt, string(line))
+	}
+
+	tabs := 0 // Tabs pressed in a row
+	for {
+		r, _, err := le.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		if r == '\t' {
+			tabs++
+		} else {
+			tabs = 0
+		}
+
+		switch r {
+		case '\t':
+			if le.complete != nil {
+				line = le.completeLine(line, tabs > 1)
+				redraw()
+			}
+		case '\r', '\n':
+			fmt.Fprint(le.out, "\r\n")
+			return string(line), nil
+		case 4: // Ctrl-D ends the input, on an empty line
+			if len(line) == 0 {
+				fmt.Fprint(le.out, "\r\n")
+				return "", io.EOF
+			}
+		case 3: // Ctrl-C discards the line
+			fmt.Fprint(le.out, "^C\r\n")
+			line = line[:0]
+			fmt.Fprint(le.out, prompt)
+		case 127, '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case 0x1b:
+			le.readEscape(&line)
+			redraw()
+		default:
+			if unicode.IsPrint(r) {
+				line = append(line, r)
+				fmt.Fprint(le.out, string(r))
+			}
+		}
+	}
+}
+
+// completeLine completes the identifier at the end of line, as far as all its
+// completions agree. If that adds nothing and list is set, e.g. on a second
+// Tab, the completions are listed on a line of their own.
+func (le *lineEditor) completeLine(line []rune, list bool) []rune {
+	start := len(line)
+	for start > 0 && isIdentifierRune(line[start-1]) {
+		start--
+	}
+
+	prefix := string(line[start:])
+	completions := le.complete(prefix)
+	if len(completions) == 0 {
+		return line
+	}
+
+	common := completions[0]
+	for _, completion := range completions[1:] {
+		for !strings.HasPrefix(completion, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	if common == prefix && list {
+		fmt.Fprintf(le.out, "\r\n%s\r\n", strings.Join(completions, "  "))
+	}
+	return append(line[:start], []rune(common)...)
+}
+
+func isIdentifierRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_' || '0' <= r && r <= '9'
+}
+
+// readEscape reads the rest of an escape sequence, replacing line with an
+// entry from the history for the up and down arrows. Other sequences, e.g. the
+// left and right arrows, are ignored.
+func (le *lineEditor) readEscape(line *[]rune) {
+	if b, err := le.in.ReadByte(); err != nil || b != '[' {
+		return
+	}
+	b, err := le.in.ReadByte()
+	if err != nil {
+		return
+	}
+
+	switch b {
+	case 'A': // \x1b[A
+		if entry, ok := le.history.Prev(); ok {
+			*line = []rune(entry)
+		}
+	case 'B': // \x1b[B
+		entry, _ := le.history.Next()
+		*line = []rune(entry)
+	}
+}
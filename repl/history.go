@@ -0,0 +1,75 @@
+package repl
+
+import (
+	"os"
+	"strings"
+)
+
+// maxHistory is how many of the newest entries SaveToFile keeps.
+const maxHistory = 1000
+
+// History is the lines entered in a session, oldest first, with a cursor for
+// stepping back and forth through them.
+type History struct {
+	entries []string
+	pos     int // entry last returned by Prev or Next, len(entries) if none
+}
+
+// Append adds line as the newest entry, unless it is blank or repeats the
+// newest entry, and moves the cursor past it.
+func (h *History) Append(line string) {
+	if strings.TrimSpace(line) != "" && (len(h.entries) == 0 || h.entries[len(h.entries)-1] != line) {
+		h.entries = append(h.entries, line)
+	}
+	h.pos = len(h.entries)
+}
+
+// Prev steps back to the entry before the last one returned. It returns false
+// when there is no older entry.
+func (h *History) Prev() (string, bool) {
+	if h.pos == 0 {
+		return "", false
+	}
+	h.pos--
+	return h.entries[h.pos], true
+}
+
+// Next steps forward to the entry after the last one returned. It returns
+// false, moving the cursor past the newest entry, when there is no newer one.
+func (h *History) Next() (string, bool) {
+	if h.pos >= len(h.entries)-1 {
+		h.pos = len(h.entries)
+		return "", false
+	}
+	h.pos++
+	return h.entries[h.pos], true
+}
+
+// SaveToFile writes the newest entries to the file at path, one per line.
+func (h *History) SaveToFile(path string) error {
+	entries := h.entries
+	if len(entries) > maxHistory {
+		entries = entries[len(entries)-maxHistory:]
+	}
+
+	var out strings.Builder
+	for _, entry := range entries {
+		out.WriteString(entry)
+		out.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(out.String()), 0o600)
+}
+
+// LoadFromFile appends the entries in the file at path, as written by
+// SaveToFile, and moves the cursor past them.
+func (h *History) LoadFromFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		h.Append(line)
+	}
+	return nil
+}
@@ -2,41 +2,250 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"monkey/evaluator"
 	"monkey/lexer"
+	"monkey/object"
 	"monkey/parser"
+	"monkey/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 const PROMPT = "#> "
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+// CONTINUATION_PROMPT is written instead of PROMPT while the input so far has
+// unclosed brackets.
+const CONTINUATION_PROMPT = "... "
+
+// Session evaluates Monkey input line by line, writing the value of each line
+// to its output. Lines with unclosed brackets are joined with the lines that
+// follow, up to the one that closes them.
+//
+// Lines starting with ':' are meta-commands, handled by the session itself:
+//
+//	:load "file.mk"    evaluate file.mk, keeping its definitions
+//	:reset             forget every definition made so far
+//	:env               list every definition made so far
+type Session struct {
+	lines   lineReader
+	out     io.Writer
+	prompt  string // written before each line is read, if set
+	history *History
+
+	evaluator *evaluator.Evaluator
+	env       *object.Environment
+}
+
+// NewSession creates a session that reads from in until EOF without
+// prompting, which is what piped input and tests want.
+func NewSession(in io.Reader, out io.Writer) *Session {
+	return &Session{
+		lines:   &scannerReader{scanner: bufio.NewScanner(in), out: out},
+		out:     out,
+		history: &History{},
+
+		evaluator: evaluator.New(
+			evaluator.WithOutput(out),
+			evaluator.WithModuleLoader(&evaluator.FileModuleLoader{SearchPaths: []string{"."}}),
+		),
+		env: object.NewEnvironment(),
+	}
+}
+
+// Run processes input until EOF or an exit or quit command, returning any
+// error from reading it. Incomplete input left at EOF is processed as it is.
+func (s *Session) Run() error {
+	var input strings.Builder
 
 	for {
-		fmt.Fprintf(out, PROMPT)
-		hasTokens := scanner.Scan()
-		if !hasTokens {
-			return
+		prompt := s.prompt
+		if prompt != "" && input.Len() != 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := s.lines.ReadLine(prompt)
+		if err != nil {
+			if input.Len() != 0 {
+				s.process(input.String())
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if command := strings.TrimSpace(line); input.Len() == 0 && (command == "exit" || command == "quit") {
+			return nil
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+		s.history.Append(line)
+		if input.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			s.runCommand(strings.TrimSpace(line))
+			continue
+		}
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
+		input.WriteString(line)
+		input.WriteString("\n")
+		if !isInputComplete(input.String()) {
 			continue
 		}
 
-		io.WriteString(out, program.String())
-		io.WriteString(out, "\n")
+		s.process(input.String())
+		input.Reset()
+	}
+}
+
+// Complete returns the names that start with prefix, sorted, out of those
+// bound in env and its outer environments and those of builtins.
+func Complete(prefix string, env *object.Environment, builtins map[string]*object.Builtin) []string {
+	seen := make(map[string]bool)
+	for ; env != nil; env = env.Outer() {
+		for _, name := range env.Names() {
+			seen[name] = true
+		}
+	}
+	for name := range builtins {
+		seen[name] = true
+	}
+
+	var completions []string
+	for name := range seen {
+		if strings.HasPrefix(name, prefix) {
+			completions = append(completions, name)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// isInputComplete reports whether every (, [ and { in input is closed. Extra
+// closing brackets do not make input incomplete; they are left for the parser
+// to report.
+func isInputComplete(input string) bool {
+	l := lexer.New(input)
+	open := 0
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			open++
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			open--
+		}
+	}
+
+	return open <= 0
+}
+
+// process evaluates input and writes its value, unless it is null, e.g. the
+// value of a let statement.
+func (s *Session) process(input string) {
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParseErrors(s.out, p.Errors())
+		return
+	}
+
+	result := s.evaluator.Eval(program, s.env)
+	if result != nil && result != object.NULL {
+		io.WriteString(s.out, result.Inspect())
+		io.WriteString(s.out, "\n")
+	}
+}
+
+func (s *Session) runCommand(command string) {
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":load":
+		if path, err := strconv.Unquote(arg); err == nil {
+			arg = path
+		}
+		s.load(arg)
+	case ":reset":
+		s.env = object.NewEnvironment()
+		io.WriteString(s.out, "Environment reset\n")
+	case ":env":
+		io.WriteString(s.out, s.env.String())
+	default:
+		fmt.Fprintf(s.out, "unknown command: %s\n", name)
 	}
 }
 
-func printParseErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, msg)
+// load evaluates the file at path in the session's environment, so that its
+// definitions can be used in the lines that follow.
+func (s *Session) load(path string) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(s.out, "could not load %q: %s\n", path, err)
+		return
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParseErrors(s.out, p.Errors())
+		return
+	}
+
+	if result := s.evaluator.Eval(program, s.env); result != nil && result.Type() == object.ERROR_OBJ {
+		io.WriteString(s.out, result.Inspect())
+		io.WriteString(s.out, "\n")
+		return
+	}
+	fmt.Fprintf(s.out, "Loaded %d statements from %q\n", len(program.Statements), path)
+}
+
+// HISTORY_FILE is where Start keeps the history, in the home directory.
+const HISTORY_FILE = ".monkey_history"
+
+// Start runs an interactive session, prompting before every line. On a
+// terminal, lines can be edited, and earlier lines recalled with the arrow
+// keys. The history is loaded from HISTORY_FILE, and saved back at the end.
+func Start(in io.Reader, out io.Writer) {
+	s := NewSession(in, out)
+	s.prompt = PROMPT
+	if f, ok := in.(*os.File); ok {
+		if editor, ok := newTerminalReader(f, out, s.history); ok {
+			editor.complete = func(prefix string) []string {
+				return Complete(prefix, s.env, s.evaluator.Builtins())
+			}
+			s.lines = editor
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		s.Run()
+		return
+	}
+
+	path := filepath.Join(home, HISTORY_FILE)
+	if err := s.history.LoadFromFile(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(out, "could not load history: %s\n", err)
+	}
+
+	s.Run()
+
+	if err := s.history.SaveToFile(path); err != nil {
+		fmt.Fprintf(out, "could not save history: %s\n", err)
+	}
+}
+
+func printParseErrors(out io.Writer, errors []parser.ParseError) {
+	for _, err := range errors {
+		io.WriteString(out, err.Error())
+		io.WriteString(out, "\n")
 	}
 }
@@ -0,0 +1,229 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/evaluator"
+	"monkey/object"
+	"monkey/test"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionRun(t *testing.T) {
+	in := strings.NewReader("let x = 5\nx + 3\n")
+	var out bytes.Buffer
+
+	err := NewSession(in, &out).Run()
+
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, out.String(), "8\n")
+}
+
+func TestIsInputComplete(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"let x = 5", true},
+		{"if (true) {", false},
+		{"if (true) {\n1\n}", true},
+		{"let f = fn(a,", false},
+		{"[1, [2, 3]", false},
+		{"{\"a\": [1]}", true},
+		{`"{"`, true},
+		{"// {", true},
+		{"}", true},
+	}
+
+	for _, tt := range tests {
+		test.AssertEqual(t, isInputComplete(tt.input), tt.want)
+	}
+}
+
+func TestREPLMultiLineInput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	in := strings.NewReader("let add = fn(a, b) {\na + b\n}\nadd(1,\n2)\n")
+	var out bytes.Buffer
+
+	Start(in, &out)
+
+	want := "#> ... ... " +
+		"#> ... 3\n" +
+		"#> "
+	test.AssertEqual(t, out.String(), want)
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := &History{}
+	h.Append("let x = 1")
+	h.Append("")
+	h.Append("x + 1")
+	h.Append("x + 1")
+	test.AssertEqual(t, h.SaveToFile(path), nil)
+
+	loaded := &History{}
+	test.AssertEqual(t, loaded.LoadFromFile(path), nil)
+
+	entry, ok := loaded.Prev()
+	test.AssertTrue(t, ok)
+	test.AssertEqual(t, entry, "x + 1")
+	entry, ok = loaded.Prev()
+	test.AssertTrue(t, ok)
+	test.AssertEqual(t, entry, "let x = 1")
+	_, ok = loaded.Prev()
+	test.AssertFalse(t, ok)
+
+	entry, ok = loaded.Next()
+	test.AssertTrue(t, ok)
+	test.AssertEqual(t, entry, "x + 1")
+	_, ok = loaded.Next()
+	test.AssertFalse(t, ok)
+}
+
+func TestStartSavesHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, HISTORY_FILE)
+	test.AssertEqual(t, os.WriteFile(path, []byte("old\n"), 0o600), nil)
+
+	var out bytes.Buffer
+	Start(strings.NewReader("1 + 2\nexit\n3 + 4\n"), &out)
+
+	// nothing after exit is read
+	test.AssertEqual(t, out.String(), "#> 3\n#> ")
+	data, err := os.ReadFile(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(data), "old\n1 + 2\n")
+}
+
+func TestLineEditor(t *testing.T) {
+	h := &History{}
+	h.Append("let x = 1")
+	h.Append("x + 1")
+
+	// up twice, down once, then edit: backspace and type
+	in := "\x1b[A\x1b[A\x1b[B\x7f2\r" + "abc\x03d\r" + "\x04"
+	var out bytes.Buffer
+	le := &lineEditor{in: bufio.NewReader(strings.NewReader(in)), out: &out, history: h}
+
+	line, err := le.ReadLine("#> ")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, line, "x + 2")
+
+	// Ctrl-C discards what was typed so far
+	line, err = le.ReadLine("#> ")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, line, "d")
+
+	_, err = le.ReadLine("#> ")
+	test.AssertEqual(t, err, io.EOF)
+}
+
+func TestSessionErrors(t *testing.T) {
+	in := strings.NewReader("let x = ;\n1 + true\n:nope\n")
+	var out bytes.Buffer
+
+	NewSession(in, &out).Run()
+
+	want := "1:9: no prefix parse function for ; found\n" +
+		"ERROR: type mismatch: INTEGER + BOOLEAN\n" +
+		"unknown command: :nope\n"
+	test.AssertEqual(t, out.String(), want)
+}
+
+func TestREPLLoadCommand(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "lib.mk")
+	test.AssertEqual(t, os.WriteFile(lib, []byte("let double = fn(x) { x * 2 };\nlet ten = 10;\n"), 0o600), nil)
+	broken := filepath.Join(dir, "broken.mk")
+	test.AssertEqual(t, os.WriteFile(broken, []byte("let y = 1;\ny + true;\n"), 0o600), nil)
+
+	in := strings.NewReader(fmt.Sprintf(":load %q\ndouble(ten)\n:load %q\ny\n:load %q\n",
+		lib, broken, filepath.Join(dir, "missing.mk")))
+	var out bytes.Buffer
+
+	NewSession(in, &out).Run()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	test.AssertEqual(t, len(lines), 5)
+	test.AssertEqual(t, lines[0], fmt.Sprintf("Loaded 2 statements from %q", lib))
+	test.AssertEqual(t, lines[1], "20")
+	// the statements before an error are still evaluated
+	test.AssertEqual(t, lines[2], "ERROR: type mismatch: INTEGER + BOOLEAN")
+	test.AssertEqual(t, lines[3], "1")
+	test.AssertTrue(t, strings.HasPrefix(lines[4], "could not load"))
+}
+
+func TestREPLReset(t *testing.T) {
+	in := strings.NewReader("let x = 5\nx\n:reset\nx\nlen(\"abc\")\n")
+	var out bytes.Buffer
+
+	NewSession(in, &out).Run()
+
+	want := "5\n" +
+		"Environment reset\n" +
+		"ERROR: identifier not found: x\n" +
+		"3\n"
+	test.AssertEqual(t, out.String(), want)
+}
+
+func TestREPLEnvCommand(t *testing.T) {
+	in := strings.NewReader("let b = [1]\nlet a = 1\n:env\n")
+	var out bytes.Buffer
+
+	NewSession(in, &out).Run()
+
+	test.AssertEqual(t, out.String(), "a: 1\nb: [1]\n")
+}
+
+func TestCompleter(t *testing.T) {
+	outer := object.NewEnvironment()
+	outer.Set("total", &object.Integer{Value: 1})
+	outer.Set("tally", &object.Integer{Value: 2})
+	env := object.NewEnclosedEnvironment(outer)
+	env.Set("temp", &object.Integer{Value: 3})
+	env.Set("total", &object.Integer{Value: 4})
+	builtins := evaluator.New().Builtins()
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"t", []string{"tally", "temp", "total", "type"}},
+		{"to", []string{"total"}},
+		{"pu", []string{"push", "puts"}},
+		{"zz", nil},
+	}
+
+	for _, tt := range tests {
+		test.AssertEqual(t, fmt.Sprint(Complete(tt.prefix, env, builtins)), fmt.Sprint(tt.want))
+	}
+}
+
+func TestLineEditorCompletion(t *testing.T) {
+	complete := func(prefix string) []string {
+		return Complete(prefix, object.NewEnvironment(), evaluator.New().Builtins())
+	}
+
+	// "pu" completes as far as "pu", so a second Tab lists push and puts
+	in := "len(fir\t[1])\r" + "pu\t\tts\r"
+	var out bytes.Buffer
+	le := &lineEditor{in: bufio.NewReader(strings.NewReader(in)), out: &out, history: &History{}, complete: complete}
+
+	line, err := le.ReadLine("#> ")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, line, "len(first[1])")
+
+	out.Reset()
+	line, err = le.ReadLine("#> ")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, line, "puts")
+	test.AssertTrue(t, strings.Contains(out.String(), "\r\npush  puts\r\n"))
+}
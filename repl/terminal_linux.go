@@ -0,0 +1,63 @@
+//go:build !noreadline
+
+package repl
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// newTerminalReader returns a lineEditor for f, if f is a terminal.
+func newTerminalReader(f *os.File, out io.Writer, history *History) (*lineEditor, bool) {
+	fd := f.Fd()
+	if _, err := getTermios(fd); err != nil {
+		return nil, false
+	}
+
+	return &lineEditor{
+		in:      bufio.NewReader(f),
+		out:     out,
+		history: history,
+		rawMode: func() (func(), error) { return makeRaw(fd) },
+	}, true
+}
+
+// makeRaw turns off echoing, line buffering and signal keys for the terminal
+// fd, leaving output processing alone so that "\n" still starts a new line.
+func makeRaw(fd uintptr) (restore func(), err error) {
+	old, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() { setTermios(fd, old) }, nil
+}
+
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&t)))
+	if errno != 0 {
+		return nil, errno
+	}
+	return &t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build noreadline || !linux
+
+package repl
+
+import (
+	"io"
+	"os"
+)
+
+// newTerminalReader never finds a terminal where raw mode is not supported,
+// so input is read a line at a time as for a pipe, without history keys.
+func newTerminalReader(f *os.File, out io.Writer, history *History) (*lineEditor, bool) {
+	return nil, false
+}
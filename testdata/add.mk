@@ -0,0 +1,2 @@
+let add = fn(a, b) { a + b };
+puts(add(1, 2));
@@ -0,0 +1,5 @@
+import "lib/square";
+
+puts(square(3));
+let x = 1 + true;
+puts("unreachable");
@@ -0,0 +1 @@
+puts(2 + 2);
@@ -0,0 +1,4 @@
+let   max=fn(a,b){if(a>b){a}else{b}}
+let nums=[3,1,2];let total=0
+for(let i=0;i<len(nums);i+=1){total+=nums[i]*(i+1)}
+puts(max(total,10))
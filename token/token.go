@@ -1,20 +1,47 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
+// Position is a location in the source. Line and Col are 1-based, Offset is
+// the 0-based byte offset into the input.
+type Position struct {
+	Line, Col, Offset int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first char of the token
+}
+
+// Comment is a // or /* */ comment. Comments are not tokens: the lexer keeps
+// them aside, for tools such as the formatter that need to write them back.
+type Comment struct {
+	Text     string   // the whole comment, including // or /* */
+	Pos      Position // position of the first /
+	Trailing bool     // whether the comment follows code on its line
 }
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"for":      FOR,
+	"while":    WHILE,
+	"null":     NULL,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"import":   IMPORT,
 }
 
 // Token types
@@ -23,28 +50,54 @@ const (
 	EOF     = "EOF"
 
 	// identifiers + literals
-	NAME = "NAME" // add, foo, x, y ...
-	INT  = "INT"  // 1234567890
+	NAME   = "NAME"   // add, foo, x, y ...
+	INT    = "INT"    // 1234567890
+	FLOAT  = "FLOAT"  // 3.14
+	STRING = "STRING" // "hello"
+
+	// interpolated strings, e.g. f"Hello ${name}!", are lexed as parts:
+	// STRING_PART (INTERP_START ... INTERP_END STRING_PART)*
+	STRING_PART  = "STRING_PART"  // Hello , !
+	INTERP_START = "INTERP_START" // ${
+	INTERP_END   = "INTERP_END"   // }
 
 	// operators
-	ASSIGN = "="
-	PLUS   = "+"
-	MINUS  = "-"
-	BANG   = "!"
-	STAR   = "*"
-	SLASH  = "/"
-	GT     = ">"
-	LT     = "<"
-	EQ     = "=="
-	NEQ    = "!="
+	ASSIGN       = "="
+	PLUS_ASSIGN  = "+="
+	MINUS_ASSIGN = "-="
+	STAR_ASSIGN  = "*="
+	SLASH_ASSIGN = "/="
+	PLUS         = "+"
+	MINUS        = "-"
+	INCREMENT    = "++"
+	DECREMENT    = "--"
+	BANG         = "!"
+	STAR         = "*"
+	POWER        = "**"
+	SLASH        = "/"
+	PERCENT      = "%"
+	GT           = ">"
+	LT           = "<"
+	GTE          = ">="
+	LTE          = "<="
+	EQ           = "=="
+	NEQ          = "!="
+	AND          = "&&"
+	OR           = "||"
+	ARROW        = "->" // reserved for function type annotations
+	PIPE         = "|>"
+	QUESTION     = "?"
 
 	// delimeters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 	LPAREN    = "("
 	RPAREN    = ")"
 	LBRACE    = "{"
 	RBRACE    = "}"
+	LBRACKET  = "["
+	RBRACKET  = "]"
 
 	// keywords
 	FUNCTION = "FUNCTION"
@@ -54,6 +107,12 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	FOR      = "FOR"
+	WHILE    = "WHILE"
+	NULL     = "NULL"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IMPORT   = "IMPORT"
 )
 
 func New(tokenType TokenType, ch byte) Token {